@@ -0,0 +1,173 @@
+package ini
+
+import (
+	"strconv"
+	"strings"
+)
+
+// append array value to the section. eg: "key[] = val"
+func (c *Ini) appendArray(section, key, val string) {
+	if c.arrays == nil {
+		c.arrays = make(map[string]map[string][]string)
+	}
+
+	if mp, ok := c.arrays[section]; ok {
+		mp[key] = append(mp[key], val)
+	} else {
+		c.arrays[section] = map[string][]string{key: {val}}
+	}
+}
+
+// delete the array value of the key, returns true on deleted.
+//
+// A key holds either a string value or an array value, the later set value will replace the other.
+func (c *Ini) delArray(section, key string) bool {
+	if _, ok := c.arrays[section][key]; !ok {
+		return false
+	}
+
+	delete(c.arrays[section], key)
+	if len(c.arrays[section]) == 0 {
+		delete(c.arrays, section)
+	}
+	return true
+}
+
+// split sub-map key. eg: "key[sub]" => "key", "sub"
+func splitSubKey(key string) (name, sub string, ok bool) {
+	ln := len(key)
+	if ln < 4 || key[ln-1] != ']' {
+		return
+	}
+
+	pos := strings.IndexByte(key, '[')
+	if pos < 1 || pos == ln-2 {
+		return
+	}
+	return key[:pos], key[pos+1 : ln-1], true
+}
+
+// sectionAnyMap merge string values and array values of the section to a map.
+// if withSubMap is true, will collect "key[sub] = val" values to map[string]string.
+//
+// NOTE: a key holds either a string value or an array value, so the values will not be overridden.
+func (c *Ini) sectionAnyMap(name string, strMap map[string]string, withSubMap ...bool) map[string]any {
+	arrMap := c.arrays[name]
+	mp := make(map[string]any, len(strMap)+len(arrMap))
+	for key, val := range strMap {
+		mp[key] = val
+	}
+
+	if len(withSubMap) > 0 && withSubMap[0] {
+		for key, val := range strMap {
+			key, sub, ok := splitSubKey(key)
+			if !ok {
+				continue
+			}
+
+			if subMp, ok := mp[key].(map[string]string); ok {
+				subMp[sub] = val
+			} else if _, has := mp[key]; !has {
+				mp[key] = map[string]string{sub: val}
+			}
+		}
+	}
+
+	for key, vals := range arrMap {
+		mp[key] = vals
+	}
+	return mp
+}
+
+// Slice get array values by key, for the values like "key[] = val".
+// If the key is not an array, will split the string value by comma.
+func Slice(key string) []string { return dc.Slice(key) }
+
+// Slice get array values by key, for the values like "key[] = val".
+// If the key is not an array, will split the string value by comma.
+//
+// Usage:
+//
+//	ini.Slice("tags")
+//	ini.Slice("section.tags")
+func (c *Ini) Slice(key string) []string {
+	if ss, ok := c.arrayValues(key); ok {
+		return ss
+	}
+	return c.Strings(key)
+}
+
+// get a copy of the array values by key
+func (c *Ini) arrayValues(key string) ([]string, bool) {
+	c.rLock()
+	defer c.rUnlock()
+
+	name, subKey := c.splitSectionAndKey(c.formatKey(key))
+	vals, ok := c.arrays[name][subKey]
+	if !ok {
+		return nil, false
+	}
+
+	// the value overridden by ENV, will split the string value.
+	if _, has := c.envValue(name, subKey); has {
+		return nil, false
+	}
+
+	ss := make([]string, len(vals))
+	copy(ss, vals)
+	return ss, true
+}
+
+// IntSlice get array values and convert to int slice
+func IntSlice(key string) []int { return dc.IntSlice(key) }
+
+// IntSlice get array values and convert to int slice.
+// If convert failed, will return nil and the error can be got by Ini.Error()
+func (c *Ini) IntSlice(key string) []int {
+	ss := c.Slice(key)
+	if len(ss) == 0 {
+		return nil
+	}
+
+	ints := make([]int, 0, len(ss))
+	for _, s := range ss {
+		iv, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			c.setErr(c.valueError(key, s, "int", err))
+			return nil
+		}
+		ints = append(ints, iv)
+	}
+	return ints
+}
+
+// SubMap get map values by key, for the values like "key[sub] = val"
+func SubMap(key string) map[string]string { return dc.SubMap(key) }
+
+// SubMap get map values by key, for the values like "key[sub] = val"
+//
+// Usage:
+//
+//	ini.SubMap("users")          // users[tom] = 23
+//	ini.SubMap("section.users")
+func (c *Ini) SubMap(key string) map[string]string {
+	c.rLock()
+	defer c.rUnlock()
+
+	name, mapKey := c.splitSectionAndKey(c.formatKey(key))
+	strMap, ok := c.data[name]
+	if !ok {
+		return nil
+	}
+
+	var mp map[string]string
+	for k, val := range strMap {
+		if k, sub, ok := splitSubKey(k); ok && k == mapKey {
+			if mp == nil {
+				mp = make(map[string]string)
+			}
+			mp[sub] = val
+		}
+	}
+	return mp
+}
@@ -0,0 +1,174 @@
+package ini_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+var arrIniStr = `
+name = inhere
+tags[] = a
+tags[] = b
+themes = x,y
+; comments for ports
+ports[] = 80
+ports[] = 443
+users[tom] = 23
+users[john] = 25
+
+[sec1]
+key = val
+types[] = t1
+types[] = t2
+invalid[] = abc
+`
+
+func TestIni_arrayValues(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.New()
+	is.NoErr(conf.LoadStrings(arrIniStr))
+
+	is.Eq([]string{"a", "b"}, conf.Slice("tags"))
+	is.Eq([]string{"t1", "t2"}, conf.Slice("sec1.types"))
+	is.Eq([]string{"x", "y"}, conf.Slice("themes"))
+	is.Empty(conf.Slice("not-exist"))
+
+	is.Eq([]int{80, 443}, conf.IntSlice("ports"))
+	is.Nil(conf.IntSlice("not-exist"))
+	is.Nil(conf.IntSlice("sec1.invalid"))
+	is.Err(conf.Error())
+
+	is.Eq(map[string]string{"tom": "23", "john": "25"}, conf.SubMap("users"))
+	is.Nil(conf.SubMap("not-exist"))
+	is.Nil(conf.SubMap("sec2.users"))
+
+	// delete
+	is.True(conf.Delete("sec1.invalid"))
+	is.Empty(conf.Slice("sec1.invalid"))
+
+	// map to struct
+	type Sec1 struct {
+		Key   string
+		Types []string
+	}
+	type Config struct {
+		Name  string
+		Tags  []string
+		Ports []int
+		Users map[string]int
+		Sec1  Sec1
+	}
+
+	cfg := &Config{}
+	is.NoErr(conf.Decode(cfg))
+	is.Eq("inhere", cfg.Name)
+	is.Eq([]string{"a", "b"}, cfg.Tags)
+	is.Eq([]int{80, 443}, cfg.Ports)
+	is.Eq(23, cfg.Users["tom"])
+	is.Eq([]string{"t1", "t2"}, cfg.Sec1.Types)
+
+	sec1 := &Sec1{}
+	is.NoErr(conf.MapStruct("sec1", sec1))
+	is.Eq([]string{"t1", "t2"}, sec1.Types)
+
+	// write back
+	buf := &bytes.Buffer{}
+	_, err := conf.WriteTo(buf)
+	is.NoErr(err)
+	str := buf.String()
+	is.StrContains(str, "tags[] = a\ntags[] = b\n")
+	is.StrContains(str, "; comments for ports\nports[] = 80\n")
+	is.StrContains(str, "users[tom] = 23\n")
+	is.StrContains(str, "[sec1]\nkey = val\ntypes[] = t1\ntypes[] = t2\n")
+
+	is.True(conf.DelSection("sec1"))
+	is.Empty(conf.Slice("sec1.types"))
+
+	conf.Reset()
+	is.Empty(conf.Slice("tags"))
+
+	// only array values
+	is.NoErr(conf.LoadStrings("[sec]\nids[] = 1"))
+	is.False(conf.IsEmpty())
+	is.Eq([]int{1}, conf.IntSlice("sec.ids"))
+	is.Nil(ini.IntSlice("not-exist"))
+	is.Nil(ini.Slice("not-exist"))
+	is.Nil(ini.SubMap("not-exist"))
+}
+
+func TestIni_Set_arrayValue(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.New()
+	is.NoErr(conf.LoadStrings("tags[] = a\ntags[] = b"))
+
+	// the string value replace the array value
+	is.NoErr(conf.Set("tags", "x,y"))
+	is.Eq("x,y", conf.Get("tags"))
+	is.Eq([]string{"x", "y"}, conf.Slice("tags"))
+
+	buf := &bytes.Buffer{}
+	_, err := conf.WriteTo(buf)
+	is.NoErr(err)
+	is.StrContains(buf.String(), "tags = x,y\n")
+	is.NotContains(buf.String(), "tags[]")
+
+	// with document
+	conf = ini.NewWithOptions(ini.KeepDocument)
+	is.NoErr(conf.LoadStrings("name = inhere\ntags[] = a\ntags[] = b\n"))
+	is.NoErr(conf.Set("tags", "x,y"))
+	is.Eq([]string{"x", "y"}, conf.Slice("tags"))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq("name = inhere\ntags = x,y\n", buf.String())
+}
+
+func TestIni_arrayValue_replaced(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.KeepDocument)
+	is.NoErr(conf.LoadStrings("[sec]\nname = inhere\ntags[] = a\ntags[] = b\nids[] = 1\n"))
+	is.True(conf.HasKey("sec.tags"))
+	is.False(conf.HasKey("sec.not-exist"))
+
+	// the string value replace the array value
+	is.NoErr(conf.LoadStrings("[sec]\ntags = x"))
+	is.Eq("x", conf.Get("sec.tags"))
+	is.Eq([]string{"x"}, conf.Slice("sec.tags"))
+
+	// SetSection replace the array value
+	is.NoErr(conf.SetSection("sec", map[string]string{"ids": "2,3"}))
+	is.Eq("2,3", conf.Get("sec.ids"))
+	is.Eq([]string{"2", "3"}, conf.Slice("sec.ids"))
+
+	buf := &bytes.Buffer{}
+	_, err := conf.WriteTo(buf)
+	is.NoErr(err)
+	is.NotContains(buf.String(), "[]")
+	is.StrContains(buf.String(), "ids = 2,3\n")
+
+	// Delete the array value
+	is.NoErr(conf.LoadStrings("[sec]\ntags[] = a"))
+	is.True(conf.Delete("sec.tags"))
+	is.False(conf.HasKey("sec.tags"))
+	is.Empty(conf.Slice("sec.tags"))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.NotContains(buf.String(), "tags")
+
+	// NewSection drop the old array values
+	is.NoErr(conf.LoadStrings("[sec]\ntags[] = a\ntags[] = b"))
+	is.NoErr(conf.NewSection("sec", map[string]string{"name": "new"}))
+	is.False(conf.HasKey("sec.tags"))
+	is.Empty(conf.Slice("sec.tags"))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq("[sec]\nname = new\n", buf.String())
+}
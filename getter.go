@@ -0,0 +1,278 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/goutil/strutil"
+)
+
+// ValueError error on convert the value to a type
+type ValueError struct {
+	// Section name of the key
+	Section string
+	// Key name in the section
+	Key string
+	// Value raw string value
+	Value string
+	// Type name of the want converted
+	Type string
+	// Err the parse error
+	Err error
+}
+
+// Error string
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("ini: cannot convert value %q of the key %q in section %q to %s: %v",
+		e.Value, e.Key, e.Section, e.Type, e.Err)
+}
+
+// Unwrap the parse error
+func (e *ValueError) Unwrap() error { return e.Err }
+
+// lookup value by key, returns ErrNotFound on key not exists,
+// returns *VarError on resolve the variable references failed.
+func (c *Ini) lookup(key string) (val string, err error) {
+	val, ok, err := c.getValueE(key)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return val, err
+}
+
+// record the convert error, not found error will be ignored.
+func (c *Ini) recordErr(err error) {
+	if !errors.Is(err, ErrNotFound) {
+		c.setErr(err)
+	}
+}
+
+// build ValueError for the key
+func (c *Ini) valueError(key, val, typ string, err error) error {
+	c.rLock()
+	defer c.rUnlock()
+
+	sec, name := c.splitSectionAndKey(c.formatKey(key))
+	return &ValueError{Section: sec, Key: name, Value: val, Type: typ, Err: err}
+}
+
+// GetString get a string value, returns error on key not exists
+func GetString(key string) (string, error) { return dc.GetString(key) }
+
+// GetString get a string value, returns error on key not exists
+func (c *Ini) GetString(key string) (string, error) { return c.lookup(key) }
+
+// GetInt get an int value, returns error on key not exists or parse failed
+func GetInt(key string) (int, error) { return dc.GetInt(key) }
+
+// GetInt get an int value, returns error on key not exists or parse failed
+func (c *Ini) GetInt(key string) (int, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	iv, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, c.valueError(key, val, "int", err)
+	}
+	return iv, nil
+}
+
+// GetInt64 get an int64 value, returns error on key not exists or parse failed
+func GetInt64(key string) (int64, error) { return dc.GetInt64(key) }
+
+// GetInt64 get an int64 value, returns error on key not exists or parse failed
+func (c *Ini) GetInt64(key string) (int64, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	i64, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, c.valueError(key, val, "int64", err)
+	}
+	return i64, nil
+}
+
+// GetUint get an uint value, returns error on key not exists or parse failed
+func GetUint(key string) (uint, error) { return dc.GetUint(key) }
+
+// GetUint get an uint value, returns error on key not exists or parse failed
+func (c *Ini) GetUint(key string) (uint, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	u64, err := strconv.ParseUint(val, 10, 0)
+	if err != nil {
+		return 0, c.valueError(key, val, "uint", err)
+	}
+	return uint(u64), nil
+}
+
+// GetFloat64 get a float64 value, returns error on key not exists or parse failed
+func GetFloat64(key string) (float64, error) { return dc.GetFloat64(key) }
+
+// GetFloat64 get a float64 value, returns error on key not exists or parse failed
+func (c *Ini) GetFloat64(key string) (float64, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	fv, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, c.valueError(key, val, "float64", err)
+	}
+	return fv, nil
+}
+
+// GetBool get a bool value, returns error on key not exists or parse failed
+func GetBool(key string) (bool, error) { return dc.GetBool(key) }
+
+// GetBool get a bool value, returns error on key not exists or parse failed
+func (c *Ini) GetBool(key string) (bool, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return false, err
+	}
+
+	bv, err := strutil.ToBool(val)
+	if err != nil {
+		return false, c.valueError(key, val, "bool", err)
+	}
+	return bv, nil
+}
+
+// GetDuration get a time.Duration value, returns error on key not exists or parse failed
+func GetDuration(key string) (time.Duration, error) { return dc.GetDuration(key) }
+
+// GetDuration get a time.Duration value, returns error on key not exists or parse failed.
+//
+// Value format please see time.ParseDuration(). eg: "300ms", "1m30s"
+func (c *Ini) GetDuration(key string) (time.Duration, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	dur, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, c.valueError(key, val, "time.Duration", err)
+	}
+	return dur, nil
+}
+
+// GetTime get a time.Time value, returns error on key not exists or parse failed
+func GetTime(key string, layout ...string) (time.Time, error) { return dc.GetTime(key, layout...) }
+
+// GetTime get a time.Time value, returns error on key not exists or parse failed.
+//
+// The default layout is time.RFC3339
+func (c *Ini) GetTime(key string, layout ...string) (time.Time, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	fmtStr := time.RFC3339
+	if len(layout) > 0 && layout[0] != "" {
+		fmtStr = layout[0]
+	}
+
+	tv, err := time.Parse(fmtStr, val)
+	if err != nil {
+		return time.Time{}, c.valueError(key, val, "time.Time", err)
+	}
+	return tv, nil
+}
+
+// GetByteSize get a byte size value, returns error on key not exists or parse failed
+func GetByteSize(key string) (uint64, error) { return dc.GetByteSize(key) }
+
+// GetByteSize get a byte size value, returns error on key not exists or parse failed.
+//
+// Value format like: "1024", "10KB", "10k", "1.5MiB", "2G". see ParseByteSize()
+func (c *Ini) GetByteSize(key string) (uint64, error) {
+	val, err := c.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := ParseByteSize(val)
+	if err != nil {
+		return 0, c.valueError(key, val, "byte size", err)
+	}
+	return size, nil
+}
+
+// byte size units, all are binary units.
+var byteUnits = map[string]uint64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+	"p": 1 << 50,
+}
+
+// ParseByteSize parse size string like "10KB", "10k", "1.5MiB", "2G" to bytes.
+//
+// Units are case-insensitive and all are binary units: K, KB, KiB = 1024
+func ParseByteSize(s string) (uint64, error) {
+	str := strings.TrimSpace(s)
+	pos := len(str)
+	for pos > 0 && !(str[pos-1] >= '0' && str[pos-1] <= '9' || str[pos-1] == '.') {
+		pos--
+	}
+
+	num, unit := str[:pos], strings.ToLower(strings.TrimSpace(str[pos:]))
+	if num == "" || num[0] == '-' {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	// eg: kb, kib
+	if ln := len(unit); ln > 1 {
+		if ln == 3 && unit[1] == 'i' && unit[2] == 'b' {
+			unit = unit[:1]
+		} else if ln == 2 && unit[1] == 'b' {
+			unit = unit[:1]
+		}
+	}
+
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit %q", unit)
+	}
+
+	if !strings.ContainsRune(num, '.') {
+		size, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if size > math.MaxUint64/multiplier {
+			return 0, fmt.Errorf("byte size %q overflows uint64", s)
+		}
+		return size * multiplier, nil
+	}
+
+	size, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// float64(math.MaxUint64) is rounded to 1<<64
+	bytes := size * float64(multiplier)
+	if bytes >= float64(math.MaxUint64) {
+		return 0, fmt.Errorf("byte size %q overflows uint64", s)
+	}
+	return uint64(bytes), nil
+}
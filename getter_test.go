@@ -0,0 +1,177 @@
+package ini_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_GetTyped(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.New()
+	err := conf.LoadStrings(`
+name = inhere
+age = 28
+debug = true
+ratio = 0.75
+[sec]
+timeout = 1m30s
+created = 2023-01-02T15:04:05Z
+day = 2023-01-02
+neg = -1
+`)
+	is.NoErr(err)
+
+	str, err := conf.GetString("name")
+	is.NoErr(err)
+	is.Eq("inhere", str)
+
+	iv, err := conf.GetInt("age")
+	is.NoErr(err)
+	is.Eq(28, iv)
+
+	i64, err := conf.GetInt64("sec.neg")
+	is.NoErr(err)
+	is.Eq(int64(-1), i64)
+
+	uv, err := conf.GetUint("age")
+	is.NoErr(err)
+	is.Eq(uint(28), uv)
+
+	bv, err := conf.GetBool("debug")
+	is.NoErr(err)
+	is.True(bv)
+
+	fv, err := conf.GetFloat64("ratio")
+	is.NoErr(err)
+	is.Eq(0.75, fv)
+
+	dv, err := conf.GetDuration("sec.timeout")
+	is.NoErr(err)
+	is.Eq(90*time.Second, dv)
+
+	tv, err := conf.GetTime("sec.created")
+	is.NoErr(err)
+	is.Eq(2023, tv.Year())
+
+	tv, err = conf.GetTime("sec.day", "2006-01-02")
+	is.NoErr(err)
+	is.Eq(time.January, tv.Month())
+
+	// not found
+	_, err = conf.GetString("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetInt("sec.not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetInt64("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetUint("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetBool("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetFloat64("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetDuration("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+	_, err = conf.GetTime("not-exist")
+	is.True(errors.Is(err, ini.ErrNotFound))
+
+	// parse error
+	_, err = conf.GetInt("name")
+	is.Err(err)
+	is.False(errors.Is(err, ini.ErrNotFound))
+
+	var ve *ini.ValueError
+	is.True(errors.As(err, &ve))
+	is.Eq(ini.DefSection(), ve.Section)
+	is.Eq("name", ve.Key)
+	is.Eq("inhere", ve.Value)
+	is.Eq("int", ve.Type)
+	is.ErrMsg(err, `ini: cannot convert value "inhere" of the key "name" in section "__default" to int: strconv.Atoi: parsing "inhere": invalid syntax`)
+
+	_, err = conf.GetDuration("sec.day")
+	is.True(errors.As(err, &ve))
+	is.Eq("sec", ve.Section)
+	is.Eq("day", ve.Key)
+
+	_, err = conf.GetUint("sec.neg")
+	is.True(errors.As(err, &ve))
+	_, err = conf.GetInt64("name")
+	is.True(errors.As(err, &ve))
+	_, err = conf.GetBool("name")
+	is.True(errors.As(err, &ve))
+	_, err = conf.GetFloat64("name")
+	is.True(errors.As(err, &ve))
+	_, err = conf.GetTime("name")
+	is.True(errors.As(err, &ve))
+}
+
+func TestGetTyped_std(t *testing.T) {
+	defer ini.Reset()
+	assert.NoErr(t, ini.LoadStrings("age = 28\ndebug = on\nratio = 1.5\ntimeout = 3s\nat = 2023-01-02T15:04:05Z"))
+
+	iv, err := ini.GetInt("age")
+	assert.NoErr(t, err)
+	assert.Eq(t, 28, iv)
+
+	bv, err := ini.GetBool("debug")
+	assert.NoErr(t, err)
+	assert.True(t, bv)
+
+	fv, err := ini.GetFloat64("ratio")
+	assert.NoErr(t, err)
+	assert.Eq(t, 1.5, fv)
+
+	dv, err := ini.GetDuration("timeout")
+	assert.NoErr(t, err)
+	assert.Eq(t, 3*time.Second, dv)
+
+	_, err = ini.GetTime("at")
+	assert.NoErr(t, err)
+	_, err = ini.GetString("not-exist")
+	assert.Err(t, err)
+	_, err = ini.GetInt64("age")
+	assert.NoErr(t, err)
+	_, err = ini.GetUint("age")
+	assert.NoErr(t, err)
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]uint64{
+		"1024":   1024,
+		"10b":    10,
+		"10k":    10 << 10,
+		"10KB":   10 << 10,
+		"10 KiB": 10 << 10,
+		"1.5MiB": 1536 << 10,
+		"2G":     2 << 30,
+		"1tb":    1 << 40,
+		"1P":     1 << 50,
+		"16383P": 16383 << 50,
+	}
+
+	for str, want := range tests {
+		size, err := ini.ParseByteSize(str)
+		assert.NoErr(t, err)
+		assert.Eq(t, want, size, "input: "+str)
+	}
+
+	for _, str := range []string{"", "MB", "10XB", "10kbit", "1.2.3M", "-1", "-1.5K", "-0.5", "16384P", "20000P", "16384.5P", "1e30"} {
+		_, err := ini.ParseByteSize(str)
+		assert.Err(t, err, "input: "+str)
+	}
+
+	conf := ini.New()
+	assert.NoErr(t, conf.LoadStrings("size = 10MB\ninvalid = 10XB"))
+	size, err := conf.GetByteSize("size")
+	assert.NoErr(t, err)
+	assert.Eq(t, uint64(10<<20), size)
+
+	_, err = conf.GetByteSize("invalid")
+	assert.Err(t, err)
+	_, err = ini.GetByteSize("not-exist")
+	assert.True(t, errors.Is(err, ini.ErrNotFound))
+}
@@ -0,0 +1,89 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gookit/ini/v2/parser"
+)
+
+// DefIncludeDepth default max depth of the include files
+const DefIncludeDepth = 10
+
+// IncludeError error on load the include files.
+type IncludeError struct {
+	// Chain of the include files. eg: ["app.ini", "conf.d/db.ini"]
+	Chain []string
+	Err   error
+}
+
+// Error message, contains the include chain.
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("ini: include %s: %s", strings.Join(e.Chain, " -> "), e.Err.Error())
+}
+
+// Unwrap the real error
+func (e *IncludeError) Unwrap() error { return e.Err }
+
+// newIncludeError with the current include chain and the file.
+func (c *Ini) newIncludeError(file string, err error) error {
+	var ie *IncludeError
+	if errors.As(err, &ie) {
+		return err
+	}
+
+	chain := make([]string, len(c.incChain), len(c.incChain)+1)
+	copy(chain, c.incChain)
+	return &IncludeError{Chain: append(chain, file), Err: err}
+}
+
+// loadInclude load the include files of the directive.
+//
+// The relative path will be resolved by the dir of the including file.
+func (c *Ini) loadInclude(inc parser.Include) error {
+	path := inc.Path
+	if n := len(c.incChain); n > 0 && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(c.incChain[n-1]), path)
+	}
+
+	files := []string{path}
+	if strings.ContainsAny(path, "*?[") {
+		var err error
+		// NOTE: the matched files are sorted
+		if files, err = filepath.Glob(path); err != nil {
+			return c.newIncludeError(path, err)
+		}
+	}
+
+	for _, file := range files {
+		if err := c.checkInclude(file); err != nil {
+			return err
+		}
+		if err := c.loadFile(file, false, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check include depth and cycles
+func (c *Ini) checkInclude(file string) error {
+	maxDepth := c.opts.MaxIncludeDepth
+	if maxDepth <= 0 {
+		maxDepth = DefIncludeDepth
+	}
+
+	if len(c.incChain) > maxDepth {
+		return c.newIncludeError(file, fmt.Errorf("exceeded max include depth %d", maxDepth))
+	}
+
+	absFile, _ := filepath.Abs(file)
+	for _, f := range c.incChain {
+		if absF, _ := filepath.Abs(f); absF == absFile {
+			return c.newIncludeError(file, errors.New("include cycle detected"))
+		}
+	}
+	return nil
+}
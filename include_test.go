@@ -0,0 +1,148 @@
+package ini_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, text := range files {
+		file := filepath.Join(dir, name)
+		assert.NoErr(t, os.MkdirAll(filepath.Dir(file), 0775))
+		assert.NoErr(t, os.WriteFile(file, []byte(text), 0664))
+	}
+}
+
+func TestIni_ParseInclude(t *testing.T) {
+	is := assert.New(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.ini": `
+name = app
+include = base.ini
+!include conf.d/*.ini
+[db]
+port = 3307
+`,
+		"base.ini":       "name = base\nenv = prod\n[db]\nport = 3306\n",
+		"conf.d/db.ini":  "[db]\nhost = 10.0.0.1\n",
+		"conf.d/log.ini": "[log]\nlevel = info\n",
+	})
+
+	conf := ini.NewWithOptions(ini.ParseInclude)
+	is.NoErr(conf.LoadFiles(filepath.Join(dir, "app.ini")))
+	// the values after the directive will override the include values
+	is.Eq("base", conf.Get("name"))
+	is.Eq("prod", conf.Get("env"))
+	is.Eq("10.0.0.1", conf.Get("db.host"))
+	is.Eq(3307, conf.Int("db.port"))
+	is.Eq("info", conf.Get("log.level"))
+
+	// reload will not load the include files as root files
+	writeFiles(t, dir, map[string]string{"conf.d/log.ini": "[log]\nlevel = debug\n"})
+	is.NoErr(conf.Reload())
+	is.Eq("debug", conf.Get("log.level"))
+	is.Eq("base", conf.Get("name"))
+
+	// not enabled
+	conf = ini.New()
+	err := conf.LoadFiles(filepath.Join(dir, "app.ini"))
+	is.Err(err)
+}
+
+func TestIni_ParseInclude_keepDocument(t *testing.T) {
+	is := assert.New(t)
+	dir := t.TempDir()
+	appStr := "; app config\nname = app\n!include db.ini\n\n; db settings\n[db]\nport = 3307\n"
+	writeFiles(t, dir, map[string]string{
+		"app.ini": appStr,
+		"db.ini":  "; db config\n[db]\nhost = 10.0.0.1\nport = 3306\n",
+	})
+
+	conf := ini.NewWithOptions(ini.ParseInclude, ini.KeepDocument)
+	is.NoErr(conf.LoadFiles(filepath.Join(dir, "app.ini")))
+	is.Eq("10.0.0.1", conf.Get("db.host"))
+	is.Eq(3307, conf.Int("db.port"))
+
+	// write back the including file only
+	buf := &bytes.Buffer{}
+	_, err := conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq(appStr, buf.String())
+
+	is.NoErr(conf.Set("port", "3308", "db"))
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq("; app config\nname = app\n!include db.ini\n\n; db settings\n[db]\nport = 3308\n", buf.String())
+
+	// the root is loaded by LoadStrings
+	rootStr := "name = app\n!include " + filepath.Join(dir, "db.ini") + "\n"
+	conf = ini.NewWithOptions(ini.ParseInclude, ini.KeepDocument)
+	is.NoErr(conf.LoadStrings(rootStr))
+	is.Eq("10.0.0.1", conf.Get("db.host"))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq(rootStr, buf.String())
+}
+
+func TestIni_ParseInclude_error(t *testing.T) {
+	is := assert.New(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.ini":       "include = b.ini\n",
+		"b.ini":       "include = sub/c.ini\n",
+		"sub/c.ini":   "include = ../a.ini\n",
+		"miss.ini":    "include = not-exist.ini\n",
+		"deep.ini":    "include = deep.ini\n",
+		"invalid.ini": "include = bad.ini\n",
+		"bad.ini":     "invalid line\n",
+	})
+
+	aFile := filepath.Join(dir, "a.ini")
+	conf := ini.NewWithOptions(ini.ParseInclude)
+	err := conf.LoadFiles(aFile)
+	is.Err(err)
+
+	var ie *ini.IncludeError
+	is.True(errors.As(err, &ie))
+	is.Eq([]string{
+		aFile,
+		filepath.Join(dir, "b.ini"),
+		filepath.Join(dir, "sub/c.ini"),
+		filepath.Join(dir, "a.ini"),
+	}, ie.Chain)
+	is.StrContains(err.Error(), "b.ini -> ")
+	is.StrContains(err.Error(), "include cycle detected")
+
+	// not exist
+	err = ini.NewWithOptions(ini.ParseInclude).LoadFiles(filepath.Join(dir, "miss.ini"))
+	is.True(errors.Is(err, os.ErrNotExist))
+	is.StrContains(err.Error(), "miss.ini -> ")
+
+	// parse error in the include file
+	err = ini.NewWithOptions(ini.ParseInclude).LoadFiles(filepath.Join(dir, "invalid.ini"))
+	is.StrContains(err.Error(), "invalid.ini -> ")
+	is.StrContains(err.Error(), "invalid line")
+
+	// max depth
+	conf = ini.NewWithOptions(ini.ParseInclude, func(opts *ini.Options) {
+		opts.MaxIncludeDepth = 2
+	})
+	writeFiles(t, dir, map[string]string{
+		"d1.ini": "include = d2.ini\n",
+		"d2.ini": "include = d3.ini\n",
+		"d3.ini": "include = d4.ini\n",
+		"d4.ini": "key = val\n",
+	})
+	err = conf.LoadFiles(filepath.Join(dir, "d1.ini"))
+	is.ErrSubMsg(err, "exceeded max include depth 2")
+}
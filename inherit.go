@@ -0,0 +1,114 @@
+package ini
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// collect section inheritance from parser, then check cycles.
+func (c *Ini) collectParents(parents map[string]string) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	if c.parents == nil {
+		c.parents = make(map[string]string, len(parents))
+	}
+
+	for child, parent := range parents {
+		c.parents[child] = parent
+		// ensure the child section exists, it may have no keys.
+		if _, ok := c.data[child]; !ok {
+			c.data[child] = make(Section)
+			if c.order != nil {
+				c.order.addSection(child)
+			}
+		}
+	}
+	return c.checkInheritCycle()
+}
+
+// check section inheritance cycles. eg: [a : b], [b : a]
+func (c *Ini) checkInheritCycle() error {
+	children := make([]string, 0, len(c.parents))
+	for child := range c.parents {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+
+	for _, child := range children {
+		chain := []string{child}
+		seen := map[string]bool{child: true}
+
+		for name, ok := c.parents[child]; ok; name, ok = c.parents[name] {
+			chain = append(chain, name)
+			if seen[name] {
+				return fmt.Errorf("ini: section inheritance cycle detected: %s", strings.Join(chain, " -> "))
+			}
+			seen[name] = true
+		}
+	}
+	return nil
+}
+
+// inheritChain get the section and its parent section names. eg: [child, parent, grandparent]
+func (c *Ini) inheritChain(name string) []string {
+	chain := []string{name}
+	// limit by the parents count for avoid cycles
+	for i := 0; i < len(c.parents); i++ {
+		parent, ok := c.parents[name]
+		if !ok {
+			break
+		}
+
+		chain = append(chain, parent)
+		name = parent
+	}
+	return chain
+}
+
+// sectionValue find value from the section, will find from parent sections on Options.ParseInherit=true
+//
+// The value will be overridden by the ENV overlay on Options.EnvOverlay is set.
+func (c *Ini) sectionValue(name, key string) (val string, ok bool) {
+	if val, ok = c.envValue(name, key); ok {
+		return
+	}
+
+	if len(c.parents) == 0 {
+		val, ok = c.data[name][key]
+		return
+	}
+
+	for _, sec := range c.inheritChain(name) {
+		if val, ok = c.data[sec][key]; ok {
+			return
+		}
+	}
+	return
+}
+
+// sectionData get the section data, will merge parent sections values on Options.ParseInherit=true
+func (c *Ini) sectionData(name string) (Section, bool) {
+	sec, ok := c.data[name]
+	if _, has := c.parents[name]; !has || !ok {
+		return c.envSection(name, sec), ok
+	}
+
+	chain := c.inheritChain(name)
+	merged := make(Section, len(sec))
+	for i := len(chain) - 1; i >= 0; i-- {
+		for key, val := range c.data[chain[i]] {
+			merged[key] = val
+		}
+	}
+	return c.envSection(name, merged), true
+}
+
+// SectionParent get the parent section name of the section, on Options.ParseInherit=true
+func (c *Ini) SectionParent(name string) string {
+	c.rLock()
+	defer c.rUnlock()
+	return c.parents[c.formatKey(name)]
+}
@@ -0,0 +1,65 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+var inheritIniStr = `
+[production]
+db_host = 10.0.0.1
+db_port = 3306
+debug = false
+[staging : production]
+db_host = 127.0.0.1
+debug = true
+[dev : staging]
+`
+
+func TestIni_ParseInherit(t *testing.T) {
+	is := assert.New(t)
+
+	conf := ini.NewWithOptions(ini.ParseInherit)
+	err := conf.LoadStrings(inheritIniStr)
+	is.NoErr(err)
+	is.True(conf.HasSection("staging"))
+	is.True(conf.HasSection("dev"))
+	is.False(conf.HasSection("staging : production"))
+	is.Eq("production", conf.SectionParent("staging"))
+
+	// fall back to parent
+	is.Eq("127.0.0.1", conf.Get("staging.db_host"))
+	is.Eq("3306", conf.Get("staging.db_port"))
+	is.Eq(3306, conf.Int("dev.db_port"))
+	is.True(conf.Bool("dev.debug"))
+	is.False(conf.HasKey("staging.not-exist"))
+
+	// merged section data
+	is.Eq(map[string]string{"db_host": "127.0.0.1", "db_port": "3306", "debug": "true"}, conf.StringMap("dev"))
+
+	st := struct {
+		DbHost string `ini:"db_host"`
+		DbPort int    `ini:"db_port"`
+	}{}
+	is.NoErr(conf.MapStruct("dev", &st))
+	is.Eq("127.0.0.1", st.DbHost)
+	is.Eq(3306, st.DbPort)
+
+	// not enabled
+	conf = ini.New()
+	is.NoErr(conf.LoadStrings(inheritIniStr))
+	is.True(conf.HasSection("staging : production"))
+	is.Eq("", conf.Get("staging.db_port"))
+}
+
+func TestIni_ParseInherit_cycle(t *testing.T) {
+	conf := ini.NewWithOptions(ini.ParseInherit)
+	err := conf.LoadStrings(`
+[a : b]
+key = val
+[b : a]
+`)
+	assert.ErrMsg(t, err, "ini: section inheritance cycle detected: a -> b -> a")
+}
@@ -16,6 +16,10 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/gookit/ini/v2/internal"
+	"github.com/gookit/ini/v2/parser"
 )
 
 // some default constants
@@ -25,22 +29,45 @@ const (
 )
 
 var (
+	// ErrNotFound error on the key does not exist
+	ErrNotFound = errors.New("ini: key does not exist in the config")
+
 	errEmptyKey = errors.New("ini: key name cannot be empty")
-	errNotFound = errors.New("ini: key does not exist in the config")
 	errReadonly = errors.New("ini: config manager instance in 'readonly' mode")
+	errNoFiles  = errors.New("ini: no files loaded by LoadFiles or LoadExists")
 	// default instance
 	dc = New()
 )
 
+// RequiredError error on the keys of `required:"true"` fields are missing on MapStruct.
+//
+// Usage:
+//
+//	var reqErr *ini.RequiredError
+//	if errors.As(err, &reqErr) {
+//		fmt.Println(reqErr.Keys)
+//	}
+type RequiredError = internal.RequiredError
+
+// ValidateError error of the Ini.Validate, contains all problems of the data.
+type ValidateError = internal.ValidateError
+
 // Section in INI config
 type Section map[string]string
 
 // Ini config data manager
+//
+// An Ini instance is safe for concurrent use by multiple goroutines:
+// the read methods(Get, StringMap, MapStruct...) use a read lock and can run in parallel,
+// the write methods(Load*, Set, Delete, SetSection, Reset...) use an exclusive lock.
+// The returned maps and slices are copies, modify them will not affect the instance.
 type Ini struct {
 	err  error
 	opts *Options
 	lock sync.RWMutex
 	data map[string]Section
+	// lock for the err, it can be set by the read methods.
+	errLock sync.Mutex
 	// regex for match user var. eg: %(VAR_NAME)s
 	varRegex *regexp.Regexp
 	// backup raw value line map, use for encode export.
@@ -50,6 +77,41 @@ type Ini struct {
 	rawBak map[string]string
 	// comments map, key is `section +"_"+ key`.
 	comments map[string]string
+	// source positions map, the key of section position is PosKey{Section: section}.
+	positions map[parser.PosKey]parser.Position
+	// section inheritance, key is child name, value is parent name.
+	parents map[string]string
+	// array values, key is section name. eg: "key[] = val"
+	arrays map[string]map[string][]string
+	// order of sections and keys. only on Options.KeepOrder=true
+	order *keyOrder
+	// document model for round-trip write. only on Options.KeepDocument=true
+	doc *parser.Document
+	// config source layers, sorted by priority. only on Options.Layered=true
+	layers []*layer
+	// load sequence for the layer priority
+	layerSeq int
+
+	// source files loaded by LoadFiles, LoadExists. use for reload
+	files []srcFile
+	// generation of the files, it will be increased on the files changed.
+	filesGen uint64
+	// serialize the Reload calls. eg: by watcher and manual
+	reloadLock sync.Mutex
+	// the files chain of current loading, use for resolve include files.
+	incChain []string
+	// polling file watcher
+	watcher *fileWatcher
+	// callbacks on data changed by reload
+	onChange []ChangeFunc
+	// registered resolvers, key is scheme. eg: "env", "file"
+	resolvers map[string]Resolver
+	// published read-only snapshot, it will be rebuilt on data changed.
+	snap atomic.Pointer[Snapshot]
+	// the data is changed on hold the write lock, the new snapshot will be published on unlock.
+	changed bool
+	// is the frozen data of a snapshot, read without lock.
+	frozen bool
 }
 
 /*************************************************************
@@ -89,7 +151,7 @@ func Reset() { dc.Reset() }
 func ResetStd() { dc = New() }
 
 func (c *Ini) ensureInit() {
-	if !c.IsEmpty() {
+	if !c.isEmpty() {
 		return
 	}
 
@@ -101,6 +163,10 @@ func (c *Ini) ensureInit() {
 		c.opts = newDefaultOptions()
 	}
 
+	if c.opts.KeepOrder && c.order == nil {
+		c.order = newKeyOrder()
+	}
+
 	// build var regex. default is `%\(([\w-:]+)\)s`
 	if c.opts.ParseVar && c.varRegex == nil {
 		// regexStr := `%\([\w-:]+\)s`
@@ -128,6 +194,8 @@ func GetOptions() Options {
 //
 // Notice: return is value. so, cannot change options
 func (c *Ini) Options() Options {
+	c.rLock()
+	defer c.rUnlock()
 	return *c.opts
 }
 
@@ -138,7 +206,9 @@ func WithOptions(opts ...func(*Options)) {
 
 // WithOptions apply some options
 func (c *Ini) WithOptions(opts ...func(*Options)) {
-	if !c.IsEmpty() {
+	c.lock.Lock()
+	defer c.unlock()
+	if !c.isEmpty() {
 		panic("ini: cannot set options after data has been load")
 	}
 
@@ -146,15 +216,18 @@ func (c *Ini) WithOptions(opts ...func(*Options)) {
 	for _, opt := range opts {
 		opt(c.opts)
 	}
+	c.changed = len(opts) > 0
 }
 
 // DefSection get default section name
 func DefSection() string {
-	return dc.opts.DefSection
+	return dc.DefSection()
 }
 
 // DefSection get default section name
 func (c *Ini) DefSection() string {
+	c.rLock()
+	defer c.rUnlock()
 	return c.opts.DefSection
 }
 
@@ -167,10 +240,12 @@ func LoadFiles(files ...string) error { return dc.LoadFiles(files...) }
 
 // LoadFiles load data from files
 func (c *Ini) LoadFiles(files ...string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	c.ensureInit()
 
 	for _, file := range files {
-		err = c.loadFile(file, false)
+		err = c.loadSource(file, false)
 		if err != nil {
 			return
 		}
@@ -183,10 +258,12 @@ func LoadExists(files ...string) error { return dc.LoadExists(files...) }
 
 // LoadExists load files, will ignore not exists
 func (c *Ini) LoadExists(files ...string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	c.ensureInit()
 
 	for _, file := range files {
-		err = c.loadFile(file, true)
+		err = c.loadSource(file, true)
 		if err != nil {
 			return
 		}
@@ -199,10 +276,19 @@ func LoadStrings(strings ...string) error { return dc.LoadStrings(strings...) }
 
 // LoadStrings load data from strings
 func (c *Ini) LoadStrings(strings ...string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	c.ensureInit()
 
 	for _, str := range strings {
-		err = c.parse(str)
+		if c.opts.Layered {
+			err = c.loadLayer(c.layerName("string"), "", func(tmp *Ini) error {
+				return tmp.parse(str, false)
+			})
+		} else {
+			err = c.parse(str, false)
+		}
+
 		if err != nil {
 			return
 		}
@@ -213,18 +299,46 @@ func (c *Ini) LoadStrings(strings ...string) (err error) {
 // LoadData load data map
 func LoadData(data map[string]Section) error { return dc.LoadData(data) }
 
-// LoadData load data map
+// LoadData load data map. the data will be copied, modify it after load will not affect the instance.
 func (c *Ini) LoadData(data map[string]Section) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	c.ensureInit()
 
-	if len(c.data) == 0 {
-		c.data = data
+	if c.opts.Layered {
+		return c.loadLayer(c.layerName("data"), "", func(tmp *Ini) error {
+			for name, sec := range data {
+				if c.opts.IgnoreCase {
+					tmp.data[strings.ToLower(name)] = mapKeyToLower(sec)
+				} else {
+					tmp.data[name] = copySection(sec)
+				}
+			}
+			if tmp.order != nil {
+				for _, name := range sortedKeys(tmp.sectionsMap()) {
+					tmp.order.addMap(name, tmp.data[name])
+				}
+			}
+			return nil
+		})
+	}
+
+	if len(c.data) == 0 && len(c.arrays) == 0 {
+		c.changed = len(data) > 0
+		for name, sec := range data {
+			c.data[name] = copySection(sec)
+		}
+		if c.order != nil {
+			for _, name := range sortedKeys(c.sectionsMap()) {
+				c.order.addMap(name, data[name])
+			}
+		}
 		return
 	}
 
 	// append or override setting data
 	for name, sec := range data {
-		err = c.SetSection(name, sec)
+		err = c.setSection(name, sec)
 		if err != nil {
 			return
 		}
@@ -232,27 +346,39 @@ func (c *Ini) LoadData(data map[string]Section) (err error) {
 	return
 }
 
-func (c *Ini) loadFile(file string, loadExist bool) (err error) {
+// load file, included is true on the file is loaded by include directive.
+func (c *Ini) loadFile(file string, loadExist, included bool) (err error) {
+
 	// open file
 	fd, err := os.Open(file)
 	if err != nil {
 		// skip not exist file
 		if os.IsNotExist(err) && loadExist {
+			c.addSrcFile(file, loadExist, included)
 			return nil
 		}
 
+		if included {
+			err = c.newIncludeError(file, err)
+		}
 		return
 	}
+
+	c.addSrcFile(file, loadExist, included)
 	//noinspection GoUnhandledErrorResult
 	defer fd.Close()
 
 	// read file content
 	bts, err := io.ReadAll(fd)
 	if err == nil {
-		err = c.parse(string(bts))
-		if err != nil {
-			return
-		}
+		// record current file, use for resolve include files.
+		c.incChain = append(c.incChain, file)
+		err = c.parse(string(bts), included)
+		c.incChain = c.incChain[:len(c.incChain)-1]
+	}
+
+	if err != nil && included {
+		err = c.newIncludeError(file, err)
 	}
 	return
 }
@@ -264,9 +390,11 @@ func (c *Ini) loadFile(file string, loadExist bool) (err error) {
 // HasKey check key exists
 func HasKey(key string) bool { return dc.HasKey(key) }
 
-// HasKey check key exists
+// HasKey check key exists, the key can be a string value or an array value.
 func (c *Ini) HasKey(key string) (ok bool) {
-	_, ok = c.GetValue(key)
+	if _, ok = c.GetValue(key); !ok {
+		_, ok = c.arrayValues(key)
+	}
 	return
 }
 
@@ -275,6 +403,8 @@ func Delete(key string) bool { return dc.Delete(key) }
 
 // Delete value by key
 func (c *Ini) Delete(key string) (ok bool) {
+	c.lock.Lock()
+	defer c.unlock()
 	if c.opts.Readonly {
 		return
 	}
@@ -285,49 +415,140 @@ func (c *Ini) Delete(key string) (ok bool) {
 	}
 
 	sec, key := c.splitSectionAndKey(key)
-	mp, ok := c.data[sec]
-	if !ok {
-		return
+	ok = c.delArray(sec, key)
+
+	mp, has := c.data[sec]
+	if has {
+		if _, has = mp[key]; has {
+			delete(mp, key)
+			c.data[sec] = mp
+			ok = true
+		}
 	}
 
 	// key in a section
-	if _, ok = mp[key]; ok {
-		delete(mp, key)
-		c.data[sec] = mp
+	if ok {
+		c.changed = true
+		c.delLayerKey(sec, key)
+		if c.order != nil {
+			c.order.delKey(sec, key)
+		}
+		if c.doc != nil {
+			c.doc.Delete(sec, key)
+			c.doc.Delete(sec, key+"[]")
+		}
 	}
 	return
 }
 
 // Reset all loaded data
 func (c *Ini) Reset() {
+	c.lock.Lock()
+	defer c.unlock()
+
+	c.changed = true
+	c.doc = nil
+	c.files = nil
+	c.filesGen++
+	c.arrays = nil
+	c.parents = nil
+	c.positions = nil
+	c.layers = nil
+	c.layerSeq = 0
+	if c.order != nil {
+		c.order = newKeyOrder()
+	}
+
 	c.data = make(map[string]Section)
 	c.rawBak = make(map[string]string, 6)
 }
 
+// Document get a copy of the retained document model. only available on Options.KeepDocument=true
+//
+// Modify the returned document will not affect the instance, please use Set, Delete... for edit.
+func (c *Ini) Document() *parser.Document {
+	c.rLock()
+	defer c.rUnlock()
+	return c.doc.Clone()
+}
+
+// KeyPosition get the source position of the key from default instance
+func KeyPosition(key string) (parser.Position, bool) { return dc.KeyPosition(key) }
+
+// KeyPosition get the source position of the key. key format like Get(): "section.key"
+//
+// The position is only available for the data loaded by LoadFiles, LoadExists, LoadStrings.
+func (c *Ini) KeyPosition(key string) (parser.Position, bool) {
+	c.rLock()
+	defer c.rUnlock()
+
+	key = c.formatKey(key)
+	if key == "" {
+		return parser.Position{}, false
+	}
+
+	name, key := c.splitSectionAndKey(key)
+	pos, ok := c.positions[parser.PosKey{Section: name, Key: key}]
+	return pos, ok
+}
+
+// SectionPosition get the source position of the section header from default instance
+func SectionPosition(name string) (parser.Position, bool) { return dc.SectionPosition(name) }
+
+// SectionPosition get the source position of the section header. eg: "[db]"
+func (c *Ini) SectionPosition(name string) (parser.Position, bool) {
+	c.rLock()
+	defer c.rUnlock()
+	pos, ok := c.positions[parser.PosKey{Section: c.formatKey(name)}]
+	return pos, ok
+}
+
 // IsEmpty config data is empty
-func IsEmpty() bool { return len(dc.data) == 0 }
+func IsEmpty() bool { return dc.IsEmpty() }
 
 // IsEmpty config data is empty
 func (c *Ini) IsEmpty() bool {
-	return len(c.data) == 0
+	c.rLock()
+	defer c.rUnlock()
+	return c.isEmpty()
+}
+
+func (c *Ini) isEmpty() bool {
+	return len(c.data) == 0 && len(c.arrays) == 0
 }
 
 // Data get all data from default instance
-func Data() map[string]Section { return dc.data }
+func Data() map[string]Section { return dc.Data() }
 
-// Data get all data
+// Data get all data, returns a copy of the data.
 func (c *Ini) Data() map[string]Section {
-	return c.data
+	c.rLock()
+	defer c.rUnlock()
+
+	data := make(map[string]Section, len(c.data))
+	for name, sec := range c.data {
+		data[name] = copySection(sec)
+	}
+	return data
 }
 
 // Error get
 func Error() error { return dc.Error() }
 
-// Error get
+// Error get the last error of get value, convert value or watch reload.
 func (c *Ini) Error() error {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
 	return c.err
 }
 
+// set the last error, it can be called on hold the read lock.
+func (c *Ini) setErr(err error) {
+	c.errLock.Lock()
+	c.err = err
+	c.errLock.Unlock()
+}
+
 /*************************************************************
  * internal helper methods
  *************************************************************/
@@ -339,6 +560,13 @@ func (c *Ini) splitSectionAndKey(key string) (string, string) {
 
 	// get val by path. eg "log.dir"
 	if strings.Contains(key, sep) {
+		// nested section. find the longest matched section. eg: "db.primary.host"
+		if c.opts.NestedSection {
+			if sec, subKey, ok := c.splitNestedKey(key); ok {
+				return sec, subKey
+			}
+		}
+
 		ss := strings.SplitN(key, sep, 2)
 		name, key = strings.TrimSpace(ss[0]), strings.TrimSpace(ss[1])
 	}
@@ -358,6 +586,27 @@ func (c *Ini) formatKey(key string) string {
 	return key
 }
 
+// sectionsMap returns a map of section names, use for sort names.
+func (c *Ini) sectionsMap() map[string]string {
+	mp := make(map[string]string, len(c.data))
+	for name := range c.data {
+		mp[name] = ""
+	}
+	return mp
+}
+
+func copySection(src map[string]string) Section {
+	if src == nil {
+		return nil
+	}
+
+	sec := make(Section, len(src))
+	for k, v := range src {
+		sec[k] = v
+	}
+	return sec
+}
+
 func mapKeyToLower(src map[string]string) map[string]string {
 	newMp := make(map[string]string)
 
@@ -2,7 +2,9 @@ package ini_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/gookit/goutil/testutil/assert"
@@ -460,3 +462,228 @@ func TestIni_WriteTo(t *testing.T) {
 	str = conf.PrettyJSON()
 	is.Eq("", str)
 }
+
+func TestIni_KeepDocument(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.KeepDocument)
+
+	err := conf.LoadStrings(iniStr)
+	is.NoErr(err)
+	is.NotNil(conf.Document())
+
+	// the document is a copy
+	conf.Document().Set("sec1", "key", "changed")
+	val, _ := conf.Document().Get("sec1", "key")
+	is.Eq("val0", val)
+
+	// write back without change
+	buf := &bytes.Buffer{}
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq(iniStr, buf.String())
+
+	is.NoErr(conf.Set("age", 30))
+	is.NoErr(conf.Set("new_key", "val", "sec1"))
+	is.True(conf.Delete("sec1.stuff"))
+	is.NoErr(conf.SetSection("sec2", map[string]string{"k": "v"}))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.Eq(`# comments in first line
+name = inhere
+age = 30
+debug = true
+ # comments for themes
+themes = a,b,c
+hasQuota1 = 'this is val'
+hasQuota2 = "this is val1" # comments for hq2
+shell = ${SHELL}
+noEnv = ${NotExist|defValue}
+
+; comments for sec1
+[sec1]
+age = 23
+; comments for key
+key = val0
+some = value
+user_name = inhere
+new_key = val
+
+[sec2]
+k = v
+`, buf.String())
+
+	is.True(conf.DelSection("sec1"))
+	is.NoErr(conf.NewSection("sec2", map[string]string{"k1": "v1"}))
+
+	buf.Reset()
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.StrContains(buf.String(), "noEnv = ${NotExist|defValue}\n\n[sec2]\nk1 = v1\n")
+	is.NotContains(buf.String(), "sec1")
+
+	conf.Reset()
+	is.Nil(conf.Document())
+}
+
+func TestIni_StrictParse(t *testing.T) {
+	is := assert.New(t)
+
+	// error with file name
+	err := ini.New().LoadFiles("testdata/error.ini")
+	var se *parser.SyntaxError
+	is.True(errors.As(err, &se))
+	is.Eq("testdata/error.ini", se.File)
+	is.Eq(1, se.Line)
+
+	conf := ini.NewWithOptions(ini.StrictParse)
+	err = conf.LoadStrings("name = inhere\nname = tom\n")
+	is.ErrSubMsg(err, `duplicate key "name" in section "__default", first defined at line 1`)
+
+	is.NoErr(ini.New().LoadStrings("name = inhere\nname = tom\n"))
+}
+
+func TestIni_DupPolicy(t *testing.T) {
+	is := assert.New(t)
+	text := "[sec]\nkey = a\nkey = b\n"
+
+	conf := ini.NewWithOptions(ini.WithDupPolicy(parser.DupFirstWins))
+	is.NoErr(conf.LoadStrings(text))
+	is.Eq("a", conf.Get("sec.key"))
+
+	conf = ini.NewWithOptions(ini.WithDupPolicy(parser.DupError))
+	is.ErrSubMsg(conf.LoadStrings(text), `line 3, column 1: duplicate key "key" in section "sec", first defined at line 2`)
+
+	conf = ini.NewWithOptions(ini.WithDupPolicy(parser.DupCollect))
+	is.NoErr(conf.LoadStrings(text))
+	is.Eq([]string{"a", "b"}, conf.Slice("sec.key"))
+
+	// load multi strings still be merged by override
+	is.NoErr(conf.LoadStrings("[sec]\nname = inhere", "[sec]\nname = tom"))
+	is.Eq("tom", conf.Get("sec.name"))
+}
+
+func TestIni_DupPolicy_keepDocument(t *testing.T) {
+	is := assert.New(t)
+	tests := []struct {
+		policy parser.DupPolicy
+		text   string
+		want   string
+	}{
+		{parser.DupLastWins, "[sec]\nkey = a\nkey = b\n", "[sec]\nkey = a\nkey = c\n"},
+		{parser.DupFirstWins, "[sec]\nkey = a\nkey = b\n", "[sec]\nkey = c\nkey = b\n"},
+		{parser.DupError, "[sec]\nkey = a\n", "[sec]\nkey = c\n"},
+		{parser.DupCollect, "[sec]\nkey = a\nkey = b\n", "[sec]\nkey = c\n"},
+	}
+
+	for _, tt := range tests {
+		conf := ini.NewWithOptions(ini.KeepDocument, ini.WithDupPolicy(tt.policy))
+		is.NoErr(conf.LoadStrings(tt.text))
+		is.NoErr(conf.Set("key", "c", "sec"))
+
+		buf := &bytes.Buffer{}
+		_, err := conf.WriteTo(buf)
+		is.NoErr(err)
+		is.Eq(tt.want, buf.String(), tt.policy.String())
+
+		// the edit is kept after reload
+		conf = ini.NewWithOptions(ini.WithDupPolicy(tt.policy))
+		is.NoErr(conf.LoadStrings(buf.String()))
+		is.Eq("c", conf.Get("sec.key"), tt.policy.String())
+	}
+}
+
+func TestIni_multiLineValue(t *testing.T) {
+	is := assert.New(t)
+
+	conf := ini.NewWithOptions(ini.IndentContinue)
+	err := conf.LoadStrings(`
+[sec]
+desc = first line
+  second line
+long = a \
+  b
+sql = """select *
+from users"""
+`)
+	is.NoErr(err)
+	is.Eq("first line\nsecond line", conf.Get("sec.desc"))
+	is.Eq("a b", conf.Get("sec.long"))
+	is.Eq("select *\nfrom users", conf.Get("sec.sql"))
+
+	// write and re-parse
+	buf := new(bytes.Buffer)
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+
+	conf2 := ini.New()
+	is.NoErr(conf2.LoadStrings(buf.String()))
+	is.Eq(conf.Data(), conf2.Data())
+}
+
+func TestIni_WriteTo_quoteValue(t *testing.T) {
+	is := assert.New(t)
+
+	conf := ini.New()
+	is.NoErr(conf.LoadStrings(`
+[sec]
+path = "C:\\data\tdir"
+lit = 'a\tb'
+`))
+	is.Eq("C:\\data\tdir", conf.Get("sec.path"))
+	is.Eq(`a\tb`, conf.Get("sec.lit"))
+	is.NoErr(conf.Set("sec.semi", " a;b "))
+
+	buf := new(bytes.Buffer)
+	_, err := conf.WriteTo(buf)
+	is.NoErr(err)
+	is.StrContains(buf.String(), `semi = " a;b "`)
+
+	conf2 := ini.New()
+	is.NoErr(conf2.LoadStrings(buf.String()))
+	is.Eq(conf.Data(), conf2.Data())
+}
+
+func TestIni_KeyPosition(t *testing.T) {
+	is := assert.New(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.ini": "name = app\n!include db.ini\n",
+		"db.ini":  "\n[db]\nhost = localhost\n",
+	})
+
+	cfg := ini.NewWithOptions(ini.ParseInclude)
+	is.NoErr(cfg.LoadFiles(filepath.Join(dir, "app.ini")))
+
+	pos, ok := cfg.KeyPosition("name")
+	is.True(ok)
+	is.Eq(filepath.Join(dir, "app.ini")+":1", pos.String())
+
+	pos, ok = cfg.KeyPosition("db.host")
+	is.True(ok)
+	is.Eq(filepath.Join(dir, "db.ini"), pos.File)
+	is.Eq(3, pos.Line)
+
+	pos, ok = cfg.SectionPosition("db")
+	is.True(ok)
+	is.Eq(2, pos.Line)
+
+	_, ok = cfg.KeyPosition("db.not-exist")
+	is.False(ok)
+
+	// loaded by string
+	is.NoErr(cfg.LoadStrings("\n[other]\nkey = val"))
+	pos, ok = cfg.KeyPosition("other.key")
+	is.True(ok)
+	is.Eq("line 3", pos.String())
+
+	// the names contains "_" will not be conflicted
+	is.NoErr(cfg.LoadStrings("[a]\nb_c = val"))
+	_, ok = cfg.KeyPosition("a_b.c")
+	is.False(ok)
+
+	cfg.Reset()
+	_, ok = cfg.KeyPosition("name")
+	is.False(ok)
+}
@@ -0,0 +1,396 @@
+// Package iniflag bind the command line flags(flag.FlagSet) to the INI config keys.
+//
+// The flag "--db.host=x" will override the key "db.host", the flags can be registered by the loaded keys or a struct.
+//
+// Usage:
+//
+//	cfg := ini.NewWithOptions(ini.Layered)
+//	b := iniflag.New(cfg, flag.CommandLine)
+//	b.ConfigFlag("config", "the config files, allow multi")
+//	b.BindKeys()
+//
+//	// eg: app --config app.ini --db.host=127.0.0.1
+//	err := b.Parse(os.Args[1:])
+package iniflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gookit/ini/v2"
+	"github.com/gookit/ini/v2/internal"
+)
+
+// DefLayerName default layer name of the flag values
+const DefLayerName = "flags"
+
+// DefPriority default priority of the flags layer, it is higher than the layers created by Load*.
+const DefPriority = 1000
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var errNotLayered = errors.New("iniflag: the Ini must be in layered mode, please enable the ini.Layered option")
+
+// Binder bind the flags to the Ini instance
+type Binder struct {
+	cfg *ini.Ini
+	fs  *flag.FlagSet
+	// LayerName the layer name of the flag values. default is DefLayerName
+	LayerName string
+	// Priority of the flags layer. default is DefPriority
+	Priority int
+
+	// the config files flag name
+	configName string
+	configs    stringList
+	// bind all loaded keys on parse
+	allKeys  bool
+	bindings []*binding
+	// flag name => binding, the registered bindings
+	values map[string]*binding
+}
+
+// binding of a flag and the config key
+type binding struct {
+	section, key string
+	usage        string
+	// default value, use on the key does not exist
+	def    string
+	isBool bool
+	// the value set by command line
+	val string
+}
+
+// String flag value
+func (b *binding) String() string { return b.val }
+
+// Set flag value
+func (b *binding) Set(s string) error {
+	b.val = s
+	return nil
+}
+
+// IsBoolFlag allow use "--debug" without value for the bool key
+func (b *binding) IsBoolFlag() bool { return b.isBool }
+
+// flagName of the binding, it is the key path. eg: "db.host", "name"
+func (b *binding) flagName(opts *ini.Options) string {
+	if b.section != opts.DefSection {
+		return b.section + opts.SectionSep + b.key
+	}
+	return b.key
+}
+
+// stringList for the repeatable config flag
+type stringList []string
+
+// String flag value
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+// Set flag value, allow multi files split by comma
+func (s *stringList) Set(val string) error {
+	for _, file := range strings.Split(val, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			*s = append(*s, file)
+		}
+	}
+	return nil
+}
+
+// New create a Binder for the Ini instance and FlagSet
+func New(cfg *ini.Ini, fs *flag.FlagSet) *Binder {
+	return &Binder{
+		cfg:       cfg,
+		fs:        fs,
+		LayerName: DefLayerName,
+		Priority:  DefPriority,
+		values:    make(map[string]*binding),
+	}
+}
+
+// ConfigFlag register the flag for choose config files, the files will be loaded by Ini.LoadFiles() on parse.
+//
+// The flag is repeatable, allow multi files split by comma. eg: "--config a.ini,b.ini", "--config a.ini --config b.ini"
+func (b *Binder) ConfigFlag(name, usage string) *Binder {
+	b.configName = name
+	b.fs.Var(&b.configs, name, usage)
+	return b
+}
+
+// ConfigFiles get the config files from the command line
+func (b *Binder) ConfigFiles() []string { return b.configs }
+
+// BindKeys bind flags for the keys, the flag name is the key path. eg: "db.host", "name"
+//
+// If keys is empty, will bind all loaded string keys on parse(after load the config files).
+func (b *Binder) BindKeys(keys ...string) *Binder {
+	if len(keys) == 0 {
+		b.allKeys = true
+		return b
+	}
+
+	opts := b.cfg.Options()
+	for _, key := range keys {
+		section, name := opts.DefSection, key
+		// nested section: find the last separator
+		pos := strings.Index(key, opts.SectionSep)
+		if opts.NestedSection {
+			pos = strings.LastIndex(key, opts.SectionSep)
+		}
+		if pos > 0 {
+			section, name = key[:pos], key[pos+len(opts.SectionSep):]
+		}
+
+		b.bindings = append(b.bindings, &binding{section: section, key: name})
+	}
+	return b
+}
+
+// BindStruct bind flags by the struct fields, the nested struct field will be a section.
+//
+//   - the key name is the tag by Options.TagName or the lower field name
+//   - the `comment` tag will be the flag usage
+//   - the field value will be the flag default value, on the key does not exist
+//   - the field type must be a basic type(string, bool, int, float...), time.Duration, time.Time or struct.
+//
+// Usage:
+//
+//	type Config struct {
+//		Debug bool `ini:"debug" comment:"enable debug mode"`
+//		Db    struct {
+//			Host string `ini:"host"`
+//		} `ini:"db"`
+//	}
+//
+//	err := b.BindStruct(&Config{})
+func (b *Binder) BindStruct(ptr any) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("iniflag: bind target must be a pointer to struct")
+	}
+
+	opts := b.cfg.Options()
+	return b.bindFields(rv.Elem(), opts.DefSection, &opts, make(map[reflect.Type]bool))
+}
+
+// bind the struct fields, visiting records the struct types on the descent path,
+// the recursive type will be skipped. eg: `type Node struct{ Child *Node }`
+func (b *Binder) bindFields(rv reflect.Value, section string, opts *ini.Options, visiting map[reflect.Type]bool) error {
+	rt := rv.Type()
+	visiting[rt] = true
+	defer delete(visiting, rt)
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name, squash, skip := internal.FieldName(sf, opts.TagName)
+		if skip {
+			continue
+		}
+		// use the lower field name on the field has no tag name
+		if name == sf.Name {
+			name = strings.ToLower(name)
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv = reflect.New(fv.Type().Elem())
+			}
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+			if visiting[fv.Type()] {
+				continue
+			}
+
+			sub := section + opts.SectionSep + name
+			switch {
+			case squash || sf.Anonymous:
+				sub = section
+			case section == opts.DefSection:
+				sub = name
+			}
+
+			if err := b.bindFields(fv, sub, opts, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isBasicKind(fv.Kind()) && fv.Type() != timeType {
+			return fmt.Errorf("iniflag: unsupported type %s of the field %s.%s", fv.Type(), rt.Name(), sf.Name)
+		}
+
+		b.bindings = append(b.bindings, &binding{
+			section: section,
+			key:     name,
+			usage:   sf.Tag.Get("comment"),
+			def:     fieldValue(fv),
+			isBool:  fv.Kind() == reflect.Bool,
+		})
+	}
+	return nil
+}
+
+// isBasicKind check the kind can be set by a flag string value
+func isBasicKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// Parse the command line args, will load the config files and set the flag values to the Ini instance.
+//
+//   - the Ini must be in the layered mode(ini.Layered option), the flag values will be set as a layer,
+//     so the loaded data will not be modified and it works on Readonly mode.
+//   - the config files are loaded before parse, so the flags can be registered by the loaded keys.
+func (b *Binder) Parse(args []string) error {
+	if !b.cfg.Options().Layered {
+		return errNotLayered
+	}
+
+	if b.configName != "" {
+		files := b.scanConfigs(args)
+		if len(files) > 0 {
+			if err := b.cfg.LoadFiles(files...); err != nil {
+				return err
+			}
+		}
+	}
+
+	b.register()
+	if err := b.fs.Parse(args); err != nil {
+		return err
+	}
+	return b.apply()
+}
+
+// register flags of the bindings, the flag name is the key path.
+func (b *Binder) register() {
+	opts := b.cfg.Options()
+	bindings := b.bindings
+	if b.allKeys {
+		for _, section := range b.cfg.SectionNames() {
+			for _, key := range b.cfg.Keys(section) {
+				bindings = append(bindings, &binding{section: section, key: key})
+			}
+		}
+	}
+
+	for _, bd := range bindings {
+		name := bd.flagName(&opts)
+
+		// the flag has been registered. eg: by BindStruct and BindKeys
+		if b.fs.Lookup(name) != nil {
+			continue
+		}
+
+		bd.val = b.cfg.Get(name, bd.def)
+		// the loaded key with bool value. eg: "debug = true"
+		if !bd.isBool {
+			val := strings.ToLower(bd.val)
+			bd.isBool = val == "true" || val == "false"
+		}
+		if bd.usage == "" {
+			bd.usage = "set the config key " + name
+		}
+
+		b.fs.Var(bd, name, bd.usage)
+		b.values[name] = bd
+	}
+}
+
+// apply the flag values to the Ini instance
+func (b *Binder) apply() error {
+	data := make(map[string]ini.Section)
+	b.fs.Visit(func(f *flag.Flag) {
+		if bd, ok := b.values[f.Name]; ok {
+			if data[bd.section] == nil {
+				data[bd.section] = make(ini.Section)
+			}
+			data[bd.section][bd.key] = bd.val
+		}
+	})
+
+	if len(data) == 0 {
+		return nil
+	}
+	return b.cfg.SetLayer(b.LayerName, b.Priority, data)
+}
+
+// scan the config files from args before parse. eg: "--config a.ini", "-config=a.ini"
+//
+// Same as flag.FlagSet.Parse, it will stop at the first non-flag argument or the terminator "--".
+func (b *Binder) scanConfigs(args []string) []string {
+	var files stringList
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		val, hasVal := "", false
+		if pos := strings.IndexByte(name, '='); pos > 0 {
+			name, val, hasVal = name[:pos], name[pos+1:], true
+		}
+
+		if name != b.configName {
+			// skip the value of other flags. eg: "--db.host 127.0.0.1"
+			if !hasVal && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") && !b.isBoolFlag(name) {
+				i++
+			}
+			continue
+		}
+
+		if !hasVal && i+1 < len(args) {
+			i++
+			val = args[i]
+		}
+		_ = files.Set(val)
+	}
+	return files
+}
+
+// check the flag is a bool flag by the registered flags and the bindings.
+func (b *Binder) isBoolFlag(name string) bool {
+	if f := b.fs.Lookup(name); f != nil {
+		bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+		return ok && bf.IsBoolFlag()
+	}
+
+	opts := b.cfg.Options()
+	for _, bd := range b.bindings {
+		if bd.isBool && bd.flagName(&opts) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// format the field value as the flag default value, the zero value is empty.
+func fieldValue(fv reflect.Value) string {
+	if fv.IsZero() {
+		return ""
+	}
+	switch v := fv.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(fv.Interface())
+}
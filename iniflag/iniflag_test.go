@@ -0,0 +1,159 @@
+package iniflag_test
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+	"github.com/gookit/ini/v2/iniflag"
+)
+
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func TestBinder_BindKeys(t *testing.T) {
+	is := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("debug = false\n[db]\nhost = localhost\nport = 3306\n"), 0644))
+
+	cfg := ini.NewWithOptions(ini.Layered, ini.Readonly)
+	fs := newFlagSet()
+	b := iniflag.New(cfg, fs).ConfigFlag("config", "config files").BindKeys()
+
+	err := b.Parse([]string{"--config", file, "--db.host=127.0.0.1", "--debug", "arg0"})
+	is.NoErr(err)
+	is.Eq([]string{file}, b.ConfigFiles())
+	is.Eq([]string{"arg0"}, fs.Args())
+
+	is.Eq("127.0.0.1", cfg.Get("db.host"))
+	is.Eq(3306, cfg.Int("db.port"))
+	is.True(cfg.Bool("debug"))
+	is.Eq("localhost", fs.Lookup("db.host").DefValue)
+
+	org, _ := cfg.Origin("db.host")
+	is.Eq(iniflag.DefLayerName, org.Layer)
+	is.Eq(iniflag.DefPriority, org.Priority)
+	org, _ = cfg.Origin("db.port")
+	is.Eq(file, org.Layer)
+
+	// unknown flag
+	b = iniflag.New(cfg, newFlagSet()).BindKeys("db.host")
+	is.ErrSubMsg(b.Parse([]string{"--db.port=3307"}), "flag provided but not defined: -db.port")
+}
+
+func TestBinder_BindStruct(t *testing.T) {
+	is := assert.New(t)
+
+	type Config struct {
+		Name    string        `ini:"name" comment:"the app name"`
+		Debug   bool          `ini:"debug"`
+		Timeout time.Duration `ini:"timeout"`
+		Db      struct {
+			Host string
+			Port int `ini:"port"`
+		} `ini:"db"`
+	}
+
+	conf := &Config{Name: "app", Timeout: 3 * time.Second}
+	conf.Db.Port = 3306
+
+	cfg := ini.NewWithOptions(ini.Layered)
+	is.NoErr(cfg.LoadStrings("[db]\nhost = localhost"))
+
+	fs := newFlagSet()
+	b := iniflag.New(cfg, fs)
+	is.NoErr(b.BindStruct(conf))
+	is.NoErr(b.Parse([]string{"-debug", "-db.port", "3307", "-timeout=5s"}))
+
+	is.Eq("the app name", fs.Lookup("name").Usage)
+	is.Eq("localhost", fs.Lookup("db.host").DefValue)
+	is.Eq("3306", fs.Lookup("db.port").DefValue)
+
+	is.True(cfg.Bool("debug"))
+	is.Eq(3307, cfg.Int("db.port"))
+	is.Eq(5*time.Second, cfg.Duration("timeout"))
+	is.False(cfg.HasKey("name"))
+
+	// the loaded data is not modified
+	is.True(cfg.RemoveLayer(iniflag.DefLayerName))
+	is.False(cfg.HasKey("debug"))
+	is.Eq("localhost", cfg.Get("db.host"))
+
+	// not layered
+	for _, cfg = range []*ini.Ini{ini.New(), ini.NewWithOptions(ini.Readonly)} {
+		b = iniflag.New(cfg, newFlagSet())
+		is.NoErr(b.BindStruct(conf))
+		is.ErrMsg(b.Parse([]string{"-debug"}), "iniflag: the Ini must be in layered mode, please enable the ini.Layered option")
+		is.False(cfg.HasKey("debug"))
+	}
+
+	is.ErrMsg(b.BindStruct(Config{}), "iniflag: bind target must be a pointer to struct")
+
+	// unsupported field type
+	type Invalid struct {
+		Name   string
+		Labels map[string]string
+	}
+	b = iniflag.New(ini.NewWithOptions(ini.Layered), newFlagSet())
+	is.ErrMsg(b.BindStruct(&Invalid{}), "iniflag: unsupported type map[string]string of the field Invalid.Labels")
+}
+
+func TestBinder_ConfigFlag_scan(t *testing.T) {
+	is := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = app\n"), 0644))
+
+	type Config struct {
+		Debug bool `ini:"debug"`
+		Db    struct {
+			Host string `ini:"host"`
+		} `ini:"db"`
+	}
+
+	// the value of other flag will be skipped
+	cfg := ini.NewWithOptions(ini.Layered)
+	b := iniflag.New(cfg, newFlagSet()).ConfigFlag("config", "config files")
+	is.NoErr(b.BindStruct(&Config{}))
+	is.NoErr(b.Parse([]string{"-debug", "--db.host", "127.0.0.1", "--config", file}))
+	is.Eq([]string{file}, b.ConfigFiles())
+	is.Eq("app", cfg.Get("name"))
+	is.Eq("127.0.0.1", cfg.Get("db.host"))
+
+	// stop at the first non-flag argument
+	cfg = ini.NewWithOptions(ini.Layered)
+	fs := newFlagSet()
+	b = iniflag.New(cfg, fs).ConfigFlag("config", "config files")
+	is.NoErr(b.Parse([]string{"arg0", "--config", file}))
+	is.Empty(b.ConfigFiles())
+	is.False(cfg.HasKey("name"))
+	is.Eq([]string{"arg0", "--config", file}, fs.Args())
+}
+
+func TestBinder_BindStruct_recursiveType(t *testing.T) {
+	is := assert.New(t)
+
+	type Node struct {
+		Name  string `ini:"name"`
+		Child *Node  `ini:"child"`
+	}
+
+	fs := newFlagSet()
+	cfg := ini.NewWithOptions(ini.Layered)
+	b := iniflag.New(cfg, fs)
+	is.NoErr(b.BindStruct(&Node{Child: &Node{}}))
+	is.NoErr(b.Parse([]string{"-name=root"}))
+
+	is.Eq("root", cfg.Get("name"))
+	is.NotNil(fs.Lookup("name"))
+	is.Nil(fs.Lookup("child.name"))
+}
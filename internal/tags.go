@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+)
+
+// RequiredError error on the required keys are missing
+type RequiredError struct {
+	// Section name of the decoding data, allow empty
+	Section string
+	// Keys path of missing keys. eg: "host", "replica.port"
+	Keys []string
+}
+
+// Error message. eg: "ini: missing required keys: db.host, db.port"
+func (e *RequiredError) Error() string {
+	keys := e.Keys
+	if e.Section != "" {
+		keys = make([]string, len(e.Keys))
+		for i, key := range e.Keys {
+			keys[i] = e.Section + "." + key
+		}
+	}
+	return "ini: missing required keys: " + strings.Join(keys, ", ")
+}
+
+// applyTags apply the `default` and `required` tag of the struct fields to data.
+// returns new data map, the input data will not be modified.
+//
+//   - default:"val"   set the default value on the key does not exist
+//   - required:"true" the key must exist and not empty
+//
+// visiting records the struct types on the descent path, use for stop at the recursive types.
+// eg: `type Node struct{ Child *Node }`
+func applyTags(tagName string, rt reflect.Type, data map[string]any, path string, missing *[]string, visiting map[reflect.Type]bool) map[string]any {
+	visiting[rt] = true
+	defer delete(visiting, rt)
+
+	newData := make(map[string]any, len(data))
+	for k, v := range data {
+		newData[k] = v
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name, squash, skip := FieldName(sf, tagName)
+		if skip {
+			continue
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// embedded struct with squash. eg: `ini:",squash"`
+		if squash && ft.Kind() == reflect.Struct {
+			if !visiting[ft] {
+				newData = applyTags(tagName, ft, newData, path, missing, visiting)
+			}
+			continue
+		}
+
+		key, val, ok := lookupKey(newData, name)
+		if !ok {
+			key = name
+		}
+
+		// nested struct
+		if isNestedStruct(ft) {
+			sub := toAnyMap(val)
+			if sub == nil {
+				// don't create empty sub map, if it has no defaults or it is a recursive type.
+				if visiting[ft] || !hasTag(ft, "default") && !hasTag(ft, "required") {
+					continue
+				}
+				sub = make(map[string]any)
+			}
+
+			sub = applyTags(tagName, ft, sub, path+name+".", missing, visiting)
+			if ok || len(sub) > 0 {
+				newData[key] = sub
+			}
+			continue
+		}
+
+		if !ok {
+			if def, has := sf.Tag.Lookup("default"); has {
+				newData[key] = def
+				continue
+			}
+		}
+
+		if sf.Tag.Get("required") == "true" && (!ok || val == "") {
+			*missing = append(*missing, path+name)
+		}
+	}
+	return newData
+}
+
+// FieldName resolve the field name by tag, same as mapstructure.
+func FieldName(sf reflect.StructField, tagName string) (name string, squash, skip bool) {
+	tag := sf.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false, true
+	}
+
+	nodes := strings.Split(tag, ",")
+	for _, opt := range nodes[1:] {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+
+	if name = nodes[0]; name == "" {
+		name = sf.Name
+	}
+	return
+}
+
+// lookupKey find key from data, the key name match is case-insensitive, same as mapstructure.
+func lookupKey(data map[string]any, name string) (string, any, bool) {
+	if val, ok := data[name]; ok {
+		return name, val, true
+	}
+
+	for key, val := range data {
+		if strings.EqualFold(key, name) {
+			return key, val, true
+		}
+	}
+	return "", nil, false
+}
+
+func toAnyMap(val any) map[string]any {
+	switch mp := val.(type) {
+	case map[string]any:
+		return mp
+	case map[string]string:
+		anyMp := make(map[string]any, len(mp))
+		for k, v := range mp {
+			anyMp[k] = v
+		}
+		return anyMp
+	}
+	return nil
+}
+
+// isNestedStruct check is a struct type to bind a section, exclude the types like time.Time
+func isNestedStruct(rt reflect.Type) bool {
+	if rt.Kind() != reflect.Struct || rt == timeType || rt == urlType {
+		return false
+	}
+	return !reflect.PointerTo(rt).Implements(textUnType)
+}
+
+// hasTag check the struct or nested struct fields has the tag
+func hasTag(rt reflect.Type, tag string) bool {
+	return hasTagIn(rt, tag, make(map[reflect.Type]bool))
+}
+
+// hasTagIn check the fields has the tag, the visited types will be skipped.
+func hasTagIn(rt reflect.Type, tag string, visited map[reflect.Type]bool) bool {
+	if visited[rt] {
+		return false
+	}
+
+	visited[rt] = true
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if _, ok := sf.Tag.Lookup(tag); ok {
+			return true
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isNestedStruct(ft) && hasTagIn(ft, tag, visited) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,37 @@
 package internal
 
-import "github.com/go-viper/mapstructure/v2"
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// TimeLayouts for decode string to time.Time
+var TimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	urlType    = reflect.TypeOf(url.URL{})
+	textUnType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
 
 // FullToStruct mapping full mode data to a struct ptr.
 func FullToStruct(tagName, defSec string, data map[string]any, ptr any) error {
-	// collect all default section data to top
+	return MapStruct(tagName, flattenFull(defSec, data), ptr)
+}
+
+// flattenFull collect all default section data to top
+func flattenFull(defSec string, data map[string]any) map[string]any {
 	anyMap := make(map[string]any, len(data)+4)
 	if defData, ok := data[defSec]; ok {
 		for key, val := range defData.(map[string]any) {
@@ -18,7 +45,7 @@ func FullToStruct(tagName, defSec string, data map[string]any, ptr any) error {
 		}
 		anyMap[group] = mp
 	}
-	return MapStruct(tagName, anyMap, ptr)
+	return anyMap
 }
 
 // LiteToStruct mapping lite mode data to a struct ptr.
@@ -43,18 +70,133 @@ func LiteToStruct(tagName, defSec string, data map[string]map[string]string, ptr
 }
 
 // MapStruct mapping data to a struct ptr.
+//
+// Support the struct tags `default:"val"` and `required:"true"`,
+// will return *RequiredError on some required keys are missing.
 func MapStruct(tagName string, data any, ptr any) error {
+	missing, err := decode(tagName, data, ptr)
+	if err == nil && len(missing) > 0 {
+		return &RequiredError{Keys: missing}
+	}
+	return err
+}
+
+// decode data to a struct ptr, returns the missing required keys.
+func decode(tagName string, data any, ptr any) (missing []string, err error) {
+	if mp, ok := data.(map[string]any); ok {
+		if rt := structType(ptr); rt != nil {
+			data = applyTags(tagName, rt, mp, "", &missing, make(map[reflect.Type]bool))
+		}
+	}
+
 	mapConf := &mapstructure.DecoderConfig{
 		Metadata: nil,
 		Result:   ptr,
 		TagName:  tagName,
 		// will auto convert string to int/uint
 		WeaklyTypedInput: true,
+		DecodeHook:       DecodeHook(),
 	}
 
 	decoder, err := mapstructure.NewDecoder(mapConf)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return missing, decoder.Decode(data)
+}
+
+// structType get the struct type of the ptr, returns nil on not a struct pointer.
+func structType(ptr any) reflect.Type {
+	rt := reflect.TypeOf(ptr)
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		return nil
+	}
+
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+	return rt
+}
+
+// DecodeHook for decode INI string values to golang types:
+//
+//   - time.Time by TimeLayouts
+//   - the types implemented encoding.TextUnmarshaler. eg: net.IP, *regexp.Regexp
+//   - time.Duration, url.URL
+//   - comma-separated string to slice. eg: "a, b" => []string{"a", "b"}
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		stringToTimeHook,
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToURLHook,
+		stringToSliceHook,
+	)
+}
+
+// decode string to time.Time, try parse by TimeLayouts
+func stringToTimeHook(from, to reflect.Type, data any) (any, error) {
+	str, ok := data.(string)
+	if !ok || to != timeType {
+		return data, nil
+	}
+
+	if str = strings.TrimSpace(str); str == "" {
+		return time.Time{}, nil
+	}
+
+	var err error
+	var t time.Time
+	for _, layout := range TimeLayouts {
+		if t, err = time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+	return nil, err
+}
+
+// decode string to url.URL or *url.URL
+func stringToURLHook(from, to reflect.Type, data any) (any, error) {
+	str, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+
+	switch to {
+	case urlType:
+		u, err := url.Parse(str)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	case reflect.PointerTo(urlType):
+		return url.Parse(str)
+	}
+	return data, nil
+}
+
+// decode comma-separated string to slice. eg: "a, b" => []string{"a", "b"}
+func stringToSliceHook(from, to reflect.Type, data any) (any, error) {
+	str, ok := data.(string)
+	if !ok || to.Kind() != reflect.Slice || to.Elem().Kind() == reflect.Uint8 {
+		return data, nil
+	}
+
+	// skip the types implemented encoding.TextUnmarshaler
+	if reflect.PointerTo(to).Implements(textUnType) {
+		return data, nil
+	}
+
+	if str = strings.TrimSpace(str); str == "" {
+		return []string{}, nil
+	}
+
+	ss := strings.Split(str, ",")
+	for i, s := range ss {
+		ss[i] = strings.TrimSpace(s)
 	}
-	return decoder.Decode(data)
+	return ss, nil
 }
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateError the problems of the data before decode to struct
+type ValidateError struct {
+	// Unknown keys and sections have no field to bind, they will be dropped on decode.
+	Unknown []string
+	// Missing keys of the `required:"true"` fields
+	Missing []string
+	// Invalid errors of the values cannot convert to the field type
+	Invalid []error
+}
+
+// Error message, one problem per line.
+func (e *ValidateError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("ini: validate failed, ")
+	sb.WriteString(strconv.Itoa(len(e.Unknown) + len(e.Missing) + len(e.Invalid)))
+	sb.WriteString(" problem(s):")
+
+	for _, key := range e.Unknown {
+		sb.WriteString("\n  unknown key " + strconv.Quote(key))
+	}
+	for _, key := range e.Missing {
+		sb.WriteString("\n  missing required key " + strconv.Quote(key))
+	}
+	for _, err := range e.Invalid {
+		sb.WriteString("\n  " + err.Error())
+	}
+	return sb.String()
+}
+
+// ValidateFull validate full mode data by the struct ptr, see Validate.
+func ValidateFull(tagName, defSec string, data map[string]any, ptr any) error {
+	return Validate(tagName, flattenFull(defSec, data), ptr)
+}
+
+// Validate the data can be decoded to the struct ptr, the ptr will not be modified.
+// returns *ValidateError on has unknown keys, missing required keys or invalid values.
+//
+// NOTE: the unknown keys are collected by walk the struct fields, because the
+// mapstructure Metadata.Unused will drop the unused keys of a struct level on it has decode errors.
+func Validate(tagName string, data map[string]any, ptr any) error {
+	rt := structType(ptr)
+	if rt == nil {
+		return errors.New("ini: validate target must be a pointer to struct")
+	}
+
+	ve := &ValidateError{}
+	unknownKeys(tagName, rt, data, "", &ve.Unknown)
+	sort.Strings(ve.Unknown)
+
+	// decode to a new value, collect the missing and invalid errors
+	missing, err := decode(tagName, data, reflect.New(rt).Interface())
+	ve.Missing = missing
+	if err != nil {
+		ve.Invalid = splitErrors(err)
+		sort.Slice(ve.Invalid, func(i, j int) bool {
+			return ve.Invalid[i].Error() < ve.Invalid[j].Error()
+		})
+	}
+
+	if len(ve.Unknown)+len(ve.Missing)+len(ve.Invalid) > 0 {
+		return ve
+	}
+	return nil
+}
+
+// unknownKeys collect the keys of data have no field to bind.
+func unknownKeys(tagName string, rt reflect.Type, data map[string]any, path string, unknown *[]string) {
+	fields, remain := structFields(tagName, rt)
+	if remain {
+		return
+	}
+
+	for key, val := range data {
+		ft, ok := fields[strings.ToLower(key)]
+		if !ok {
+			// the raw sub key of map value. eg: "key[sub]", it has been collected to the "key"
+			if pos := strings.IndexByte(key, '['); pos > 0 && strings.HasSuffix(key, "]") {
+				if _, ok = fields[strings.ToLower(key[:pos])]; ok {
+					continue
+				}
+			}
+
+			*unknown = append(*unknown, path+key)
+			continue
+		}
+
+		if isNestedStruct(ft) {
+			if sub := toAnyMap(val); sub != nil {
+				unknownKeys(tagName, ft, sub, path+key+".", unknown)
+			}
+		}
+	}
+}
+
+// structFields get the fields of struct, key is lower field name. remain is true on has `,remain` field.
+func structFields(tagName string, rt reflect.Type) (fields map[string]reflect.Type, remain bool) {
+	fields = make(map[string]reflect.Type, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name, squash, skip := FieldName(sf, tagName)
+		if skip {
+			continue
+		}
+		if strings.Contains(sf.Tag.Get(tagName), ",remain") {
+			return nil, true
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if squash && ft.Kind() == reflect.Struct {
+			sub, subRemain := structFields(tagName, ft)
+			if subRemain {
+				return nil, true
+			}
+			for k, t := range sub {
+				fields[k] = t
+			}
+			continue
+		}
+		fields[strings.ToLower(name)] = ft
+	}
+	return
+}
+
+// split the joined errors, the mapstructure will wrap the joined errors at top.
+func splitErrors(err error) []error {
+	var jErr interface{ Unwrap() []error }
+	if errors.As(err, &jErr) {
+		var errs []error
+		for _, e := range jErr.Unwrap() {
+			errs = append(errs, splitErrors(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
@@ -0,0 +1,181 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefVarDepth default max depth of the nested variable references
+const DefVarDepth = 10
+
+var (
+	// ErrVarCycle error on the variable references has a cycle. eg: a = %(b)s, b = %(a)s
+	ErrVarCycle = errors.New("variable reference cycle detected")
+	// ErrVarDepth error on the nested variable references too deep
+	ErrVarDepth = errors.New("exceeded max variable reference depth")
+	// ErrVarUnresolved error on the variable reference cannot be resolved, only on Options.StrictVar=true
+	ErrVarUnresolved = errors.New("unresolved variable reference")
+
+	// extended variable reference. eg: "${key}", "${section:key}"
+	extVarRegex = regexp.MustCompile(`\$\{(?:([^${}:|\s]+):)?([^${}:|\s]+)\}`)
+	// ENV reference with default value. eg: "${SHELL|/bin/sh}"
+	envDefRegex = regexp.MustCompile(`\$\{[^${}]+\|[^${}]*\}`)
+)
+
+// VarError error on resolve the variable references
+type VarError struct {
+	// Chain of the resolving keys. eg: ["sec.url", "sec.host"]
+	Chain []string
+	Err   error
+}
+
+// Error message, contains the resolving chain.
+func (e *VarError) Error() string {
+	return fmt.Sprintf("ini: resolve var %s: %s", strings.Join(e.Chain, " -> "), e.Err.Error())
+}
+
+// Unwrap the real error
+func (e *VarError) Unwrap() error { return e.Err }
+
+// variable reference in the value
+type varRef struct {
+	// raw text. eg: "%(name)s", "${sec:name}"
+	text string
+	// section name, only for "${section:key}"
+	section string
+	name    string
+	// is extended reference "${...}"
+	ext bool
+}
+
+// parseVarReference resolve the variable references in the value of the key recursively.
+//
+//	http://%(host)s:%(port)s/Portal
+//	%(section.key)s key in the section
+//	${key} ${section:key} on Options.ExtendedVar=true
+//
+// On error, will return the raw value and *VarError.
+func (c *Ini) parseVarReference(section, key, val string) (string, error) {
+	return c.resolveVars(section, key, val, []string{c.varKeyPath(section, key)})
+}
+
+func (c *Ini) resolveVars(section, key, val string, chain []string) (string, error) {
+	refs := c.findVarRefs(val)
+	if len(refs) == 0 {
+		return val, nil
+	}
+
+	maxDepth := c.opts.MaxVarDepth
+	if maxDepth <= 0 {
+		maxDepth = DefVarDepth
+	}
+
+	oldNew := make([]string, 0, len(refs)*2)
+	for _, ref := range refs {
+		sec, name, refVal, ok := c.lookupVarRef(section, key, ref)
+		if !ok {
+			// fall back to ENV for "${NAME}"
+			if ref.ext && ref.section == "" && c.opts.ParseEnv {
+				if envVal, has := os.LookupEnv(ref.name); has {
+					oldNew = append(oldNew, ref.text, envVal)
+					continue
+				}
+			}
+
+			if c.opts.StrictVar {
+				return val, &VarError{Chain: chain, Err: fmt.Errorf("%w %s", ErrVarUnresolved, ref.text)}
+			}
+			continue
+		}
+
+		// check cycle and depth
+		refKey := c.varKeyPath(sec, name)
+		newChain := make([]string, len(chain), len(chain)+1)
+		copy(newChain, chain)
+		newChain = append(newChain, refKey)
+
+		for _, k := range chain {
+			if k == refKey {
+				return val, &VarError{Chain: newChain, Err: ErrVarCycle}
+			}
+		}
+		if len(chain) > maxDepth {
+			return val, &VarError{Chain: newChain, Err: fmt.Errorf("%w %d", ErrVarDepth, maxDepth)}
+		}
+
+		refVal, err := c.resolveVars(sec, name, refVal, newChain)
+		if err != nil {
+			return val, err
+		}
+		oldNew = append(oldNew, ref.text, refVal)
+	}
+
+	return strings.NewReplacer(oldNew...).Replace(val), nil
+}
+
+// find all variable references in the value
+func (c *Ini) findVarRefs(val string) (refs []varRef) {
+	if c.varRegex != nil && strings.Contains(val, c.opts.VarOpen) {
+		oLen, cLen := len(c.opts.VarOpen), len(c.opts.VarClose)
+		for _, text := range c.varRegex.FindAllString(val, -1) {
+			refs = append(refs, varRef{text: text, name: text[oLen : len(text)-cLen]})
+		}
+	}
+
+	if c.opts.ExtendedVar && strings.Contains(val, "${") {
+		for _, ss := range extVarRegex.FindAllStringSubmatch(val, -1) {
+			// is resolver expression. eg: "${env:HOME}"
+			if ss[1] != "" && c.opts.ParseResolver && c.resolver(ss[1]) != nil {
+				continue
+			}
+			refs = append(refs, varRef{text: ss[0], section: ss[1], name: ss[2], ext: true})
+		}
+	}
+	return
+}
+
+// lookup the value of variable reference, returns the section and key of the value.
+func (c *Ini) lookupVarRef(section, key string, ref varRef) (sec, name, val string, ok bool) {
+	name = ref.name
+	if c.opts.IgnoreCase {
+		name = strings.ToLower(name)
+	}
+
+	// extended reference: "${key}" find from current section then the default section
+	if ref.ext {
+		if ref.section != "" {
+			sec = c.formatKey(ref.section)
+			val, ok = c.sectionValue(sec, name)
+			return
+		}
+
+		for _, sec = range []string{section, c.opts.DefSection} {
+			if val, ok = c.sectionValue(sec, name); ok {
+				return
+			}
+		}
+		return
+	}
+
+	// first, find from current section
+	if name != key {
+		if val, ok = c.sectionValue(section, name); ok {
+			return section, name, val, true
+		}
+	}
+
+	sec, name = c.splitSectionAndKey(c.formatKey(ref.name))
+	val, ok = c.sectionValue(sec, name)
+	return
+}
+
+// key path for the VarError. the keys of the default section has no section prefix.
+func (c *Ini) varKeyPath(section, key string) string {
+	if section == c.opts.DefSection {
+		return key
+	}
+	return section + c.opts.SectionSep + key
+}
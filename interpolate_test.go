@@ -0,0 +1,95 @@
+package ini_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestParseVar_nested(t *testing.T) {
+	is := assert.New(t)
+	text := `
+host = localhost
+base = http://%(host)s:%(sec.port)s
+[sec]
+port = 8080
+url = %(base)s/api
+api = %(url)s/v1
+notExist = %(varNotExist)s/api
+cycle1 = %(cycle2)s
+cycle2 = %(cycle1)s
+`
+	conf := ini.NewWithOptions(ini.ParseVar)
+	err := conf.LoadStrings(text)
+	is.NoErr(err)
+
+	is.Eq("http://localhost:8080/api", conf.Get("sec.url"))
+	is.Eq("http://localhost:8080/api/v1", conf.Get("sec.api"))
+	is.Eq("%(varNotExist)s/api", conf.Get("sec.notExist"))
+	is.NoErr(conf.Error())
+
+	// cycle
+	is.Eq("%(cycle2)s", conf.Get("sec.cycle1"))
+	is.ErrMsg(conf.Error(), "ini: resolve var sec.cycle1 -> sec.cycle2 -> sec.cycle1: variable reference cycle detected")
+
+	_, err = conf.GetString("sec.cycle2")
+	var ve *ini.VarError
+	is.True(errors.As(err, &ve))
+	is.True(errors.Is(err, ini.ErrVarCycle))
+	is.Eq([]string{"sec.cycle2", "sec.cycle1", "sec.cycle2"}, ve.Chain)
+
+	// max depth
+	conf = ini.NewWithOptions(ini.ParseVar, func(opts *ini.Options) {
+		opts.MaxVarDepth = 1
+	})
+	is.NoErr(conf.LoadStrings(text))
+	_, err = conf.GetString("sec.api")
+	is.True(errors.Is(err, ini.ErrVarDepth))
+	is.ErrMsg(err, "ini: resolve var sec.api -> sec.url -> base: exceeded max variable reference depth 1")
+
+	// strict mode
+	conf = ini.NewWithOptions(ini.ParseVar, ini.StrictVar)
+	is.NoErr(conf.LoadStrings("key = %(notExist)s/api\nref = %(key)s"))
+	_, err = conf.GetString("key")
+	is.True(errors.Is(err, ini.ErrVarUnresolved))
+	is.ErrMsg(err, "ini: resolve var key: unresolved variable reference %(notExist)s")
+	_, err = conf.GetString("ref")
+	is.ErrMsg(err, "ini: resolve var ref -> key: unresolved variable reference %(notExist)s")
+}
+
+func TestParseVar_extended(t *testing.T) {
+	is := assert.New(t)
+	is.NoErr(os.Setenv("INI_TEST_EXT_VAR", "env-val"))
+	defer os.Unsetenv("INI_TEST_EXT_VAR")
+
+	conf := ini.NewWithOptions(ini.ExtendedVar)
+	err := conf.LoadStrings(`
+home = /home/inhere
+shell = ${NOT_EXIST_ENV|/bin/sh}
+[paths]
+dir = ${home}/data
+log = ${dir}/logs
+old = %(home)s/old
+env = ${INI_TEST_EXT_VAR}
+[app]
+log = ${paths:log}/app.log
+name = ${notExist}
+`)
+	is.NoErr(err)
+
+	opts := conf.Options()
+	is.True(opts.ParseVar)
+	is.True(opts.ExtendedVar)
+
+	is.Eq("/bin/sh", conf.Get("shell"))
+	is.Eq("/home/inhere/data", conf.Get("paths.dir"))
+	is.Eq("/home/inhere/data/logs", conf.Get("paths.log"))
+	is.Eq("/home/inhere/old", conf.Get("paths.old"))
+	is.Eq("env-val", conf.Get("paths.env"))
+	is.Eq("/home/inhere/data/logs/app.log", conf.Get("app.log"))
+	is.Eq("${notExist}", conf.Get("app.name"))
+	is.Eq("/home/inhere/data/logs/app.log", conf.StringMap("app")["log"])
+}
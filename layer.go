@@ -0,0 +1,397 @@
+package ini
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gookit/ini/v2/parser"
+)
+
+// LayerRuntime the layer name of the values changed by Set, SetSection and NewSection
+// in the layered mode, it has the highest priority.
+const LayerRuntime = "runtime"
+
+var errNotLayered = errors.New("ini: the layer operations only available on Options.Layered=true")
+
+// Layer info of a config source in the layered mode
+type Layer struct {
+	// Name of the layer. eg: file path for LoadFiles, "string#1" for LoadStrings, "data#2" for LoadData
+	Name string
+	// File path of the layer, it is empty on the layer is not loaded from file.
+	File string
+	// Priority the higher priority layer will override the lower on lookup.
+	// The layers created by Load* use the load sequence 1, 2, 3... as priority.
+	Priority int
+}
+
+// KeyOrigin the origin of the effective value of a key
+type KeyOrigin struct {
+	// Layer name of supplied the value
+	Layer string
+	// Priority of the layer
+	Priority int
+	// Position of the key in the source, the line is 0 on the value is not loaded from file or string.
+	Position parser.Position
+}
+
+// layer data of a config source
+type layer struct {
+	Layer
+	data      map[string]Section
+	arrays    map[string]map[string][]string
+	parents   map[string]string
+	positions map[parser.PosKey]parser.Position
+	order     *keyOrder
+	rawBak    map[string]string
+	comments  map[string]string
+}
+
+// Layers get all layers of the default instance
+func Layers() []Layer { return dc.Layers() }
+
+// Layers get all layers info, returns by the lookup order(the highest priority first).
+func (c *Ini) Layers() []Layer {
+	c.rLock()
+	defer c.rUnlock()
+
+	ls := make([]Layer, 0, len(c.layers))
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		ls = append(ls, c.layers[i].Layer)
+	}
+	return ls
+}
+
+// SetLayer add or replace a layer for the default instance
+func SetLayer(name string, priority int, data map[string]Section) error {
+	return dc.SetLayer(name, priority, data)
+}
+
+// SetLayer add a named layer by data, the existed layer will be replaced. only on Options.Layered=true
+//
+// It is allowed on Options.Readonly=true, use for overlay sources. eg: ENV, command line flags
+//
+// Usage:
+//
+//	cfg := ini.NewWithOptions(ini.Layered)
+//	err := cfg.LoadExists("/etc/app.ini", "/home/inhere/.app.ini")
+//	err = cfg.SetLayer("flags", 100, map[string]ini.Section{"db": {"host": "127.0.0.1"}})
+func (c *Ini) SetLayer(name string, priority int, data map[string]Section) error {
+	c.lock.Lock()
+	defer c.unlock()
+	if !c.opts.Layered {
+		return errNotLayered
+	}
+
+	c.ensureInit()
+	c.removeLayer(name)
+	l := c.newLayer(name, "", priority)
+	for sec, mp := range data {
+		sec = c.formatKey(sec)
+		if c.opts.IgnoreCase {
+			l.data[sec] = mapKeyToLower(mp)
+		} else {
+			l.data[sec] = copySection(mp)
+		}
+	}
+
+	c.addLayer(l)
+	return nil
+}
+
+// RemoveLayer remove the layer by name, returns false on not found.
+func (c *Ini) RemoveLayer(name string) bool {
+	c.lock.Lock()
+	defer c.unlock()
+
+	if c.removeLayer(name) {
+		c.mergeLayers()
+		return true
+	}
+	return false
+}
+
+// Origin get the origin of the key value from default instance
+func Origin(key string) (KeyOrigin, bool) { return dc.Origin(key) }
+
+// Origin get which layer supplied the effective value of the key. only on Options.Layered=true
+//
+// On the value is overridden by the ENV overlay, the layer is LayerEnv.
+//
+// Usage:
+//
+//	org, ok := cfg.Origin("db.host")
+//	fmt.Println(org.Layer, org.Position) // "/etc/app.ini /etc/app.ini:3"
+func (c *Ini) Origin(key string) (org KeyOrigin, ok bool) {
+	c.rLock()
+	defer c.rUnlock()
+
+	if key = c.formatKey(key); key == "" {
+		return
+	}
+
+	sec, key := c.splitSectionAndKey(key)
+	if _, has := c.envValue(sec, key); has {
+		return KeyOrigin{Layer: LayerEnv, Priority: math.MaxInt}, true
+	}
+
+	// find by the section inheritance chain, same as sectionValue
+	for _, name := range c.inheritChain(sec) {
+		for i := len(c.layers) - 1; i >= 0; i-- {
+			l := c.layers[i]
+			if !l.hasKey(name, key) {
+				continue
+			}
+
+			org = KeyOrigin{Layer: l.Name, Priority: l.Priority, Position: l.positions[parser.PosKey{Section: name, Key: key}]}
+			return org, true
+		}
+	}
+	return
+}
+
+func (l *layer) hasKey(section, key string) bool {
+	if _, ok := l.data[section][key]; ok {
+		return true
+	}
+	_, ok := l.arrays[section][key]
+	return ok
+}
+
+// new empty layer, the priority will use the next load sequence on it is 0.
+func (c *Ini) newLayer(name, file string, priority int) *layer {
+	if priority == 0 {
+		c.layerSeq++
+		priority = c.layerSeq
+	}
+
+	return &layer{
+		Layer: Layer{Name: name, File: file, Priority: priority},
+		data:  make(map[string]Section),
+	}
+}
+
+// load a source to a new layer. eg: file, string
+func (c *Ini) loadLayer(name, file string, load func(tmp *Ini) error) error {
+	tmp := &Ini{opts: c.opts, rawBak: make(map[string]string, 6)}
+	tmp.ensureInit()
+
+	err := load(tmp)
+	for _, f := range tmp.files {
+		c.addSrcFile(f.path, f.exist, f.included)
+	}
+	// the empty source will not create layer. eg: not exists file
+	if err != nil || tmp.isEmpty() {
+		return err
+	}
+
+	l := c.newLayer(name, file, 0)
+	l.data = tmp.data
+	l.arrays = tmp.arrays
+	l.parents = tmp.parents
+	l.positions = tmp.positions
+	l.order = tmp.order
+	l.rawBak = tmp.rawBak
+	l.comments = tmp.comments
+
+	c.addLayer(l)
+	return nil
+}
+
+// load source file, will create a layer for the file on Options.Layered=true
+func (c *Ini) loadSource(file string, loadExist bool) error {
+	if !c.opts.Layered {
+		return c.loadFile(file, loadExist, false)
+	}
+
+	return c.loadLayer(file, file, func(tmp *Ini) error {
+		return tmp.loadFile(file, loadExist, false)
+	})
+}
+
+// add layer by the priority, then merge the layers data.
+func (c *Ini) addLayer(l *layer) {
+	c.layers = append(c.layers, l)
+	c.sortLayers()
+	c.mergeLayers()
+}
+
+// sort layers by priority, the lower priority first.
+func (c *Ini) sortLayers() {
+	sort.SliceStable(c.layers, func(i, j int) bool {
+		return c.layers[i].Priority < c.layers[j].Priority
+	})
+}
+
+func (c *Ini) removeLayer(name string) bool {
+	for i, l := range c.layers {
+		if l.Name == name {
+			c.layers = append(c.layers[:i:i], c.layers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// replace the file layers by the reloaded layers, the other layers will be kept.
+func (c *Ini) replaceFileLayers(layers []*layer) {
+	priorities := make(map[string]int, len(c.layers))
+	kept := make([]*layer, 0, len(c.layers)+len(layers))
+	for _, l := range c.layers {
+		if l.File == "" {
+			kept = append(kept, l)
+		} else {
+			priorities[l.Name] = l.Priority
+		}
+	}
+
+	for _, l := range layers {
+		if p, ok := priorities[l.Name]; ok {
+			l.Priority = p
+		} else {
+			c.layerSeq++
+			l.Priority = c.layerSeq
+		}
+	}
+
+	c.layers = append(kept, layers...)
+	c.sortLayers()
+	c.mergeLayers()
+}
+
+// merge the layers data by priority, the higher priority values will override the lower.
+func (c *Ini) mergeLayers() {
+	c.changed = true
+	c.data = make(map[string]Section)
+	c.arrays = nil
+	c.parents = nil
+	c.positions = nil
+	c.comments = nil
+	c.rawBak = make(map[string]string, 6)
+	if c.order != nil {
+		c.order = newKeyOrder()
+	}
+
+	for _, l := range c.layers {
+		names := sortedKeys(l.sectionsMap())
+		if l.order != nil {
+			names = l.order.sections
+		}
+
+		for _, sec := range names {
+			if secMp, ok := l.data[sec]; ok {
+				mp, has := c.data[sec]
+				if !has {
+					mp = make(Section, len(secMp))
+					c.data[sec] = mp
+				}
+
+				for key, val := range secMp {
+					mp[key] = val
+					delete(c.arrays[sec], key)
+				}
+			}
+			for key, vals := range l.arrays[sec] {
+				delete(c.data[sec], key)
+				if c.arrays == nil {
+					c.arrays = make(map[string]map[string][]string)
+				}
+				if c.arrays[sec] == nil {
+					c.arrays[sec] = make(map[string][]string, len(l.arrays[sec]))
+				}
+				c.arrays[sec][key] = append([]string(nil), vals...)
+			}
+
+			if c.order == nil {
+				continue
+			}
+			if l.order != nil {
+				c.order.addSection(sec)
+				for _, key := range l.order.keys[sec] {
+					c.order.add(sec, key)
+				}
+			} else {
+				c.order.addMap(sec, l.data[sec])
+			}
+		}
+
+		for child, parent := range l.parents {
+			if c.parents == nil {
+				c.parents = make(map[string]string)
+			}
+			c.parents[child] = parent
+		}
+		for key, val := range l.rawBak {
+			c.rawBak[key] = val
+		}
+		for key, val := range l.comments {
+			if c.comments == nil {
+				c.comments = make(map[string]string)
+			}
+			c.comments[key] = val
+		}
+		c.addPositions(l.positions)
+	}
+}
+
+// sections of the layer, contains the sections only have array values.
+func (l *layer) sectionsMap() map[string]string {
+	mp := make(map[string]string, len(l.data)+len(l.arrays))
+	for name := range l.data {
+		mp[name] = ""
+	}
+	for name := range l.arrays {
+		mp[name] = ""
+	}
+	return mp
+}
+
+// set values to the runtime layer, on Set, SetSection, NewSection.
+func (c *Ini) setLayerValues(section string, values map[string]string) {
+	if !c.opts.Layered {
+		return
+	}
+
+	var rl *layer
+	for _, l := range c.layers {
+		if l.Name == LayerRuntime {
+			rl = l
+			break
+		}
+	}
+	if rl == nil {
+		rl = c.newLayer(LayerRuntime, "", math.MaxInt)
+		c.layers = append(c.layers, rl)
+	}
+
+	sec, ok := rl.data[section]
+	if !ok {
+		sec = make(Section, len(values))
+		rl.data[section] = sec
+	}
+	for key, val := range values {
+		sec[key] = val
+	}
+}
+
+// delete the key from all layers, on Delete.
+func (c *Ini) delLayerKey(section, key string) {
+	for _, l := range c.layers {
+		delete(l.data[section], key)
+		delete(l.arrays[section], key)
+	}
+}
+
+// delete the section from all layers, on DelSection, NewSection.
+func (c *Ini) delLayerSection(section string) {
+	for _, l := range c.layers {
+		delete(l.data, section)
+		delete(l.arrays, section)
+	}
+}
+
+// the auto layer name of LoadStrings, LoadData. eg: "string#1"
+func (c *Ini) layerName(kind string) string {
+	return kind + "#" + strconv.Itoa(c.layerSeq+1)
+}
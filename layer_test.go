@@ -0,0 +1,109 @@
+package ini_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_Layered(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+	sysFile := filepath.Join(dir, "sys.ini")
+	userFile := filepath.Join(dir, "user.ini")
+	is.NoErr(os.WriteFile(sysFile, []byte("name = sys\n[db]\nhost = db.local\nport = 3306\n"), 0644))
+	is.NoErr(os.WriteFile(userFile, []byte("[db]\nport = 3307\n"), 0644))
+
+	cfg := ini.NewWithOptions(ini.Layered)
+	is.NoErr(cfg.LoadStrings("name = default\ndebug = false\n[db]\nhost = localhost"))
+	is.NoErr(cfg.LoadExists(sysFile, filepath.Join(dir, "not-exist.ini"), userFile))
+
+	is.Eq([]ini.Layer{
+		{Name: userFile, File: userFile, Priority: 3},
+		{Name: sysFile, File: sysFile, Priority: 2},
+		{Name: "string#1", Priority: 1},
+	}, cfg.Layers())
+
+	is.Eq("sys", cfg.Get("name"))
+	is.Eq("false", cfg.Get("debug"))
+	is.Eq("db.local", cfg.Get("db.host"))
+	is.Eq(3307, cfg.Int("db.port"))
+
+	org, ok := cfg.Origin("db.port")
+	is.True(ok)
+	is.Eq(userFile, org.Layer)
+	is.Eq(userFile+":2", org.Position.String())
+
+	org, ok = cfg.Origin("debug")
+	is.True(ok)
+	is.Eq("string#1", org.Layer)
+	is.Eq(2, org.Position.Line)
+
+	_, ok = cfg.Origin("not-exist")
+	is.False(ok)
+
+	// overlay layer with priority
+	is.NoErr(cfg.SetLayer("flags", 100, map[string]ini.Section{"db": {"port": "3308"}}))
+	is.Eq(3308, cfg.Int("db.port"))
+	org, _ = cfg.Origin("db.port")
+	is.Eq("flags", org.Layer)
+	is.Eq(100, org.Priority)
+
+	// runtime layer
+	is.NoErr(cfg.Set("port", 3309, "db"))
+	is.Eq(3309, cfg.Int("db.port"))
+	org, _ = cfg.Origin("db.port")
+	is.Eq(ini.LayerRuntime, org.Layer)
+
+	// remove layer, the lower values are not lost
+	is.True(cfg.Delete("db.port"))
+	is.False(cfg.HasKey("db.port"))
+	is.True(cfg.RemoveLayer("flags"))
+	is.False(cfg.RemoveLayer("flags"))
+	is.Eq("db.local", cfg.Get("db.host"))
+	is.NoErr(cfg.SetLayer("flags", 100, map[string]ini.Section{"db": {"host": "127.0.0.1"}}))
+	is.Eq("127.0.0.1", cfg.Get("db.host"))
+	is.True(cfg.RemoveLayer("flags"))
+	is.Eq("db.local", cfg.Get("db.host"))
+
+	// reload keep the not file layers
+	is.NoErr(cfg.Set("name", "runtime"))
+	is.NoErr(os.WriteFile(sysFile, []byte("[db]\nhost = db.new\n"), 0644))
+	is.NoErr(cfg.Reload())
+	is.Eq("db.new", cfg.Get("db.host"))
+	is.Eq("runtime", cfg.Get("name"))
+	is.Eq("false", cfg.Get("debug"))
+	org, _ = cfg.Origin("db.host")
+	is.Eq(sysFile, org.Layer)
+	is.Len(cfg.Layers(), 4)
+
+	// not layered
+	cfg = ini.New()
+	is.ErrMsg(cfg.SetLayer("flags", 1, nil), "ini: the layer operations only available on Options.Layered=true")
+	is.NoErr(cfg.LoadStrings("name = app"))
+	_, ok = cfg.Origin("name")
+	is.False(ok)
+}
+
+func TestIni_Layered_readonly(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := ini.NewWithOptions(ini.Layered, ini.Readonly, ini.KeepOrder)
+	is.NoErr(cfg.LoadStrings("[db]\nhost = localhost\ntags[] = a\ntags[] = b"))
+	is.NoErr(cfg.LoadData(map[string]ini.Section{"db": {"user": "root"}}))
+	is.Eq([]string{"host", "user"}, cfg.Keys("db"))
+	is.Eq([]string{"a", "b"}, cfg.Slice("db.tags"))
+
+	// the higher layer string value override the array value
+	is.NoErr(cfg.SetLayer("env", 10, map[string]ini.Section{"db": {"tags": "c,d", "host": "127.0.0.1"}}))
+	is.Eq([]string{"c", "d"}, cfg.Slice("db.tags"))
+	is.Eq("127.0.0.1", cfg.Get("db.host"))
+
+	org, _ := cfg.Origin("db.user")
+	is.Eq("data#2", org.Layer)
+	is.ErrMsg(cfg.Set("name", "app"), "ini: config manager instance in 'readonly' mode")
+}
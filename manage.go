@@ -1,11 +1,14 @@
 package ini
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gookit/goutil/maputil"
 	"github.com/gookit/goutil/strutil"
@@ -24,11 +27,20 @@ func GetValue(key string) (string, bool) { return dc.GetValue(key) }
 // GetValue a value by key string.
 //
 // you can use '.' split for get value in a special section
+//
+// On resolve the variable references failed, the error can be got by Ini.Error().
 func (c *Ini) GetValue(key string) (val string, ok bool) {
-	if !c.opts.Readonly {
-		c.lock.Lock()
-		defer c.lock.Unlock()
+	val, ok, err := c.getValueE(key)
+	if err != nil {
+		c.setErr(err)
 	}
+	return
+}
+
+// get value and returns the error on resolve the variable references.
+func (c *Ini) getValueE(key string) (val string, ok bool, err error) {
+	c.rLock()
+	defer c.rUnlock()
 
 	if key = c.formatKey(key); key == "" {
 		return
@@ -36,16 +48,20 @@ func (c *Ini) GetValue(key string) (val string, ok bool) {
 
 	// get section data
 	name, key := c.splitSectionAndKey(key)
-	strMap, ok := c.data[name]
-	if !ok {
+	if val, ok = c.sectionValue(name, key); !ok {
 		return
 	}
 
-	val, ok = strMap[key]
-
 	// if enable parse var refer
 	if c.opts.ParseVar {
-		val = c.parseVarReference(key, val, strMap)
+		if val, err = c.parseVarReference(name, key, val); err != nil {
+			return
+		}
+	}
+
+	// resolve the expressions. eg: "${env:HOME}"
+	if c.opts.ParseResolver {
+		val, err = c.resolveValue(val)
 	}
 
 	// if opts.ParseEnv is true. will parse like: "${SHELL}"
@@ -55,21 +71,6 @@ func (c *Ini) GetValue(key string) (val string, ok bool) {
 	return
 }
 
-func (c *Ini) getValue(key string) (val string, ok bool) {
-	if key = c.formatKey(key); key == "" {
-		return
-	}
-
-	// get section data
-	name, key := c.splitSectionAndKey(key)
-
-	// get value
-	if strMap, has := c.data[name]; has {
-		val, ok = strMap[key]
-	}
-	return
-}
-
 // Get a value by key string.
 // you can use '.' split for get value in a special section
 func Get(key string, defVal ...string) string { return dc.Get(key, defVal...) }
@@ -146,7 +147,7 @@ func (c *Ini) tryInt64(key string) (value int64, ok bool) {
 
 	value, err := strconv.ParseInt(strVal, 10, 0)
 	if err != nil {
-		c.err = err
+		c.setErr(err)
 	}
 	return
 }
@@ -170,9 +171,79 @@ func (c *Ini) Bool(key string, defVal ...bool) (value bool) {
 	var err error
 	value, err = strutil.ToBool(rawVal)
 	if err != nil {
-		c.err = err
+		c.setErr(err)
+	}
+
+	return
+}
+
+// Float64 get a float64 value, if not found return default value
+func Float64(key string, defVal ...float64) float64 { return dc.Float64(key, defVal...) }
+
+// Float64 get a float64 value, if not found or convert failed return default value
+func (c *Ini) Float64(key string, defVal ...float64) (value float64) {
+	value, err := c.GetFloat64(key)
+	if err != nil {
+		c.recordErr(err)
+		if len(defVal) > 0 {
+			value = defVal[0]
+		}
 	}
+	return
+}
+
+// Duration get a time.Duration value, if not found return default value
+func Duration(key string, defVal ...time.Duration) time.Duration {
+	return dc.Duration(key, defVal...)
+}
 
+// Duration get a time.Duration value, if not found or convert failed return default value.
+//
+// Value format please see time.ParseDuration(). eg: "300ms", "1m30s"
+func (c *Ini) Duration(key string, defVal ...time.Duration) (value time.Duration) {
+	value, err := c.GetDuration(key)
+	if err != nil {
+		c.recordErr(err)
+		if len(defVal) > 0 {
+			value = defVal[0]
+		}
+	}
+	return
+}
+
+// ByteSize get a byte size value, if not found return default value
+func ByteSize(key string, defVal ...uint64) uint64 { return dc.ByteSize(key, defVal...) }
+
+// ByteSize get a byte size value, if not found or convert failed return default value.
+//
+// Value format like: "1024", "10KB", "10k", "1.5MiB", "2G". see ParseByteSize()
+func (c *Ini) ByteSize(key string, defVal ...uint64) (value uint64) {
+	value, err := c.GetByteSize(key)
+	if err != nil {
+		c.recordErr(err)
+		if len(defVal) > 0 {
+			value = defVal[0]
+		}
+	}
+	return
+}
+
+// Time get a time.Time value by layout, if not found return default value
+func Time(key, layout string, defVal ...time.Time) time.Time {
+	return dc.Time(key, layout, defVal...)
+}
+
+// Time get a time.Time value by layout, if not found or convert failed return default value.
+//
+// If layout is empty, will use time.RFC3339
+func (c *Ini) Time(key, layout string, defVal ...time.Time) (value time.Time) {
+	value, err := c.GetTime(key, layout)
+	if err != nil {
+		c.recordErr(err)
+		if len(defVal) > 0 {
+			value = defVal[0]
+		}
+	}
 	return
 }
 
@@ -199,36 +270,46 @@ func StringMap(name string) map[string]string { return dc.StringMap(name) }
 // Section get a section data map. is alias of StringMap()
 func (c *Ini) Section(name string) Section { return c.StringMap(name) }
 
-// StringMap get a section data map by name
-func (c *Ini) StringMap(name string) (mp map[string]string) {
-	name = c.formatKey(name)
+// StringMap get a section data map by name, returns a copy of the section data.
+func (c *Ini) StringMap(name string) map[string]string {
+	c.rLock()
+	defer c.rUnlock()
+	return c.stringMap(c.formatKey(name))
+}
+
+func (c *Ini) stringMap(name string) (mp map[string]string) {
 	// empty name, return default section
 	if name == "" {
 		name = c.opts.DefSection
 	}
 
-	mp, ok := c.data[name]
+	sec, ok := c.sectionData(name)
 	if !ok {
 		return
 	}
 
-	// if c.opts.ParseVar || c.opts.ParseEnv {
-	if c.opts.ParseVar {
-		for k, v := range mp {
-			// parser Var refer
-			if c.opts.ParseVar {
-				v = c.parseVarReference(k, v, mp)
-			}
+	// NOTE: don't modify the raw data, the values will be resolved on each get.
+	mp = make(map[string]string, len(sec))
+	for k, v := range sec {
+		var err error
+		// parser Var refer
+		if c.opts.ParseVar {
+			v, err = c.parseVarReference(name, k, v)
+		}
+		if c.opts.ParseResolver && err == nil {
+			v, err = c.resolveValue(v)
+		}
+		if err != nil {
+			c.setErr(err)
+		}
 
-			// parse ENV. like: "${SHELL}"
-			// if c.opts.ParseEnv {
-			// 	v = envutil.ParseEnvValue(v)
-			// }
+		// parse ENV. like: "${SHELL}"
+		// if c.opts.ParseEnv {
+		// 	v = envutil.ParseEnvValue(v)
+		// }
 
-			mp[k] = v
-		}
+		mp[k] = v
 	}
-
 	return
 }
 
@@ -252,21 +333,81 @@ func (c *Ini) MapTo(ptr any) error { return c.MapStruct("", ptr) }
 //	user := &Db{}
 //	ini.MapStruct("user", &user)
 func (c *Ini) MapStruct(key string, ptr any) error {
+	c.rLock()
+	defer c.rUnlock()
+
 	// parts data of the config
 	if key != "" {
-		data := c.StringMap(key)
-		if len(data) == 0 {
-			return errNotFound
+		name := c.formatKey(key)
+		data := c.stringMap(name)
+
+		if c.opts.NestedSection {
+			if mp := c.nestedData(name); len(mp) > 0 {
+				return withSection(internal.MapStruct(c.opts.TagName, mp, ptr), name)
+			}
+			return ErrNotFound
 		}
-		return internal.MapStruct(c.opts.TagName, data, ptr)
+
+		if len(data) == 0 && len(c.arrays[name]) == 0 {
+			return ErrNotFound
+		}
+		return withSection(internal.MapStruct(c.opts.TagName, c.sectionAnyMap(name, data, true), ptr), name)
 	}
 
 	// ----- binding all data -----
-	data := make(map[string]map[string]string, len(c.data))
-	for name, value := range c.data {
-		data[name] = value
+	return internal.FullToStruct(c.opts.TagName, c.opts.DefSection, c.allData(), ptr)
+}
+
+// all data for binding struct, key is section name.
+func (c *Ini) allData() map[string]any {
+	if c.opts.NestedSection {
+		return c.nestedData("")
+	}
+
+	// the values are resolved as same as Get()
+	data := make(map[string]any, len(c.data))
+	for name := range c.data {
+		data[name] = c.sectionAnyMap(name, c.stringMap(name), true)
+	}
+	for name := range c.arrays {
+		if _, ok := data[name]; !ok {
+			data[name] = c.sectionAnyMap(name, nil, true)
+		}
+	}
+	return data
+}
+
+// Validate all data of the default instance by the struct ptr
+func Validate(ptr any) error { return dc.Validate(ptr) }
+
+// Validate all data can be decoded to the struct ptr, before call Decode.
+// The ptr will not be modified.
+//
+// Returns *ValidateError on has some problems:
+//
+//   - unknown keys and sections, they have no field to bind and will be dropped by Decode. eg: typos
+//   - missing keys of the `required:"true"` fields
+//   - the values cannot convert to the field type
+//
+// Usage:
+//
+//	cfg := &Config{}
+//	if err := ini.Validate(cfg); err != nil {
+//		// handle error
+//	}
+//	err = ini.MapStruct("", cfg)
+func (c *Ini) Validate(ptr any) error {
+	c.rLock()
+	defer c.rUnlock()
+	return internal.ValidateFull(c.opts.TagName, c.opts.DefSection, c.allData(), ptr)
+}
+
+// withSection set the section name for the RequiredError
+func withSection(err error, name string) error {
+	if reqErr, ok := err.(*RequiredError); ok {
+		reqErr.Section = name
 	}
-	return internal.LiteToStruct(c.opts.TagName, c.opts.DefSection, data, ptr)
+	return err
 }
 
 /*************************************************************
@@ -284,19 +425,19 @@ func Set(key string, val any, section ...string) error {
 //
 // if section is empty, will set to default section
 func (c *Ini) Set(key string, val any, section ...string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	if c.opts.Readonly {
 		return errReadonly
 	}
 
 	c.ensureInit()
-	c.lock.Lock()
-	defer c.lock.Unlock()
-
 	key = c.formatKey(key)
 	if key == "" {
 		return errEmptyKey
 	}
 
+	c.changed = true
 	// section name
 	group := c.opts.DefSection
 	if len(section) > 0 && section[0] != "" {
@@ -315,55 +456,170 @@ func (c *Ini) Set(key string, val any, section ...string) (err error) {
 	}
 
 	c.data[group] = sec
+	c.setLayerValues(group, Section{key: strVal})
+	if c.order != nil {
+		c.order.add(group, key)
+	}
+
+	// the string value replace the array value. eg: "key[] = val"
+	isArr := c.delArray(group, key)
+	if c.doc != nil {
+		if isArr {
+			c.doc.Delete(group, key+"[]")
+		}
+		c.doc.Set(group, key, strVal)
+	}
 	return
 }
 
 // SetSection if not exist, add new section. If existed, will merge to old section.
+// The values will be copied, modify it after set will not affect the instance.
 func (c *Ini) SetSection(name string, values map[string]string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.setSection(name, values)
+}
+
+func (c *Ini) setSection(name string, values map[string]string) (err error) {
 	if c.opts.Readonly {
 		return errReadonly
 	}
 
+	c.ensureInit()
+	c.changed = true
 	name = c.formatKey(name)
+	c.syncDocSection(name, values, false)
+
+	if c.opts.IgnoreCase {
+		values = mapKeyToLower(values)
+	} else {
+		values = copySection(values)
+	}
+
+	// the string values replace the array values
+	for key := range values {
+		if c.delArray(name, key) && c.doc != nil {
+			c.doc.Delete(name, key+"[]")
+		}
+	}
+
+	c.setLayerValues(name, values)
+	if c.order != nil {
+		c.order.addMap(name, values)
+	}
+
 	if old, ok := c.data[name]; ok {
 		c.data[name] = maputil.MergeStringMap(values, old, c.opts.IgnoreCase)
 		return
 	}
 
-	if c.opts.IgnoreCase {
-		values = mapKeyToLower(values)
-	}
 	c.data[name] = values
 	return
 }
 
-// NewSection add new section data, existed will be replaced
+// NewSection add new section data, existed will be replaced.
+// The values will be copied, modify it after set will not affect the instance.
 func (c *Ini) NewSection(name string, values map[string]string) (err error) {
+	c.lock.Lock()
+	defer c.unlock()
 	if c.opts.Readonly {
 		return errReadonly
 	}
 
+	c.ensureInit()
+	c.changed = true
 	if c.opts.IgnoreCase {
 		name = strings.ToLower(name)
 		c.data[name] = mapKeyToLower(values)
 	} else {
-		c.data[name] = values
+		c.data[name] = copySection(values)
+	}
+
+	// the old array values are replaced too
+	delete(c.arrays, name)
+
+	c.delLayerSection(name)
+	c.setLayerValues(name, c.data[name])
+	if c.order != nil {
+		c.order.clearKeys(name)
+		c.order.addMap(name, c.data[name])
 	}
+
+	c.syncDocSection(name, values, true)
 	return
 }
 
+// sync section values to the document. if replace is true, will delete the keys not in values.
+func (c *Ini) syncDocSection(name string, values map[string]string, replace bool) {
+	if c.doc == nil {
+		return
+	}
+
+	if replace {
+		for _, key := range c.doc.Keys(name) {
+			if _, ok := values[key]; !ok {
+				c.doc.Delete(name, key)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		c.doc.Set(name, key, values[key])
+	}
+}
+
 /*************************************************************
  * config dump
  *************************************************************/
 
 // PrettyJSON translate to pretty JSON string
 func (c *Ini) PrettyJSON() string {
+	c.rLock()
+	defer c.rUnlock()
 	if len(c.data) == 0 {
 		return ""
 	}
 
-	out, _ := json.MarshalIndent(c.data, "", "    ")
-	return string(out)
+	if c.order == nil {
+		out, _ := json.MarshalIndent(c.data, "", "    ")
+		return string(out)
+	}
+
+	// encode by the loaded order
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, name := range c.sectionNames() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		bs, _ := json.Marshal(name)
+		buf.Write(bs)
+		buf.WriteString(":{")
+		for j, key := range c.keys(name) {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+
+			bs, _ = json.Marshal(key)
+			buf.Write(bs)
+			buf.WriteByte(':')
+			bs, _ = json.Marshal(c.data[name][key])
+			buf.Write(bs)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+
+	out := &bytes.Buffer{}
+	_ = json.Indent(out, buf.Bytes(), "", "    ")
+	return out.String()
 }
 
 // WriteToFile write config data to a file
@@ -378,15 +634,64 @@ func (c *Ini) WriteToFile(file string) (int64, error) {
 }
 
 // WriteTo out an INI File representing the current state to a writer.
+//
+// If Options.KeepDocument is true, will write back the retained document,
+// keep the original comments, blank lines and ordering.
 func (c *Ini) WriteTo(out io.Writer) (n int64, err error) {
-	mp := make(map[string]map[string]string, len(c.data))
+	c.rLock()
+	defer c.rUnlock()
+	if c.doc != nil {
+		return c.doc.WriteTo(out)
+	}
+
+	mp := make(map[string]any, len(c.data))
 	for group, secMp := range c.data {
-		mp[group] = secMp
+		mp[group] = c.sectionAnyMap(group, secMp)
+	}
+	for group := range c.arrays {
+		if _, ok := mp[group]; !ok {
+			mp[group] = c.sectionAnyMap(group, nil)
+		}
+	}
+
+	var secOrder []string
+	var keyOrder map[string][]string
+	if c.order != nil {
+		secOrder = c.sectionNames()
+		keyOrder = make(map[string][]string, len(c.order.keys))
+		for name, keys := range c.order.keys {
+			keyOrder[name] = keys
+		}
+	}
+
+	// keep the section inheritance declaration. eg: [child : parent]
+	for child, parent := range c.parents {
+		secMp, ok := mp[child]
+		if !ok {
+			continue
+		}
+
+		name := child + " : " + parent
+		delete(mp, child)
+		mp[name] = secMp
+
+		// rename in the order, keep the section position
+		if c.order != nil {
+			for i, sec := range secOrder {
+				if sec == child {
+					secOrder[i] = name
+				}
+			}
+			keyOrder[name] = keyOrder[child]
+		}
 	}
 
 	bs, err := parser.EncodeWith(mp, &parser.EncodeOptions{
 		Comments:   c.comments,
 		DefSection: c.opts.DefSection,
+		// order of sections and keys
+		SectionOrder: secOrder,
+		KeyOrder:     keyOrder,
 		// raw value map
 		RawValueMap:   c.rawBak,
 		AddExportDate: true,
@@ -406,20 +711,40 @@ func (c *Ini) WriteTo(out io.Writer) (n int64, err error) {
 
 // HasSection has section
 func (c *Ini) HasSection(name string) bool {
-	name = c.formatKey(name)
-	_, ok := c.data[name]
-	return ok
+	c.rLock()
+	defer c.rUnlock()
+	return c.hasSection(c.formatKey(name))
 }
 
 // DelSection del section by name
 func (c *Ini) DelSection(name string) (ok bool) {
+	c.lock.Lock()
+	defer c.unlock()
 	if c.opts.Readonly {
 		return
 	}
 
 	name = c.formatKey(name)
-	if _, ok = c.data[name]; ok {
+	if _, has := c.arrays[name]; has {
+		delete(c.arrays, name)
+		ok = true
+	}
+
+	if _, has := c.data[name]; has {
 		delete(c.data, name)
+		ok = true
+	}
+
+	if ok {
+		c.changed = true
+		c.delLayerSection(name)
+		if c.order != nil {
+			c.order.delSection(name)
+		}
+
+		if c.doc != nil {
+			c.doc.DelSection(name)
+		}
 	}
 	return
 }
@@ -429,9 +754,21 @@ func SectionKeys(withDefSection bool) (ls []string) {
 	return dc.SectionKeys(withDefSection)
 }
 
-// SectionKeys get all section names
+// SectionKeys get all section names.
+// if Options.KeepOrder is true, returns by the loaded order.
 func (c *Ini) SectionKeys(withDefSection bool) (ls []string) {
+	c.rLock()
+	defer c.rUnlock()
+
 	defaultSection := c.opts.DefSection
+	if c.order != nil {
+		for _, section := range c.sectionNames() {
+			if withDefSection || section != defaultSection {
+				ls = append(ls, section)
+			}
+		}
+		return
+	}
 
 	for section := range c.data {
 		if !withDefSection && section == defaultSection {
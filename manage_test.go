@@ -1,11 +1,19 @@
 package ini_test
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gookit/goutil/testutil/assert"
 	"github.com/gookit/ini/v2"
+	"github.com/gookit/ini/v2/parser"
 )
 
 func TestIni_Get(t *testing.T) {
@@ -301,3 +309,359 @@ tag = golang
 	// invalid param
 	is.Err(conf.MapTo(nil))
 }
+
+func TestIni_Float64_Duration_ByteSize_Time(t *testing.T) {
+	is := assert.New(t)
+	defer ini.Reset()
+
+	err := ini.LoadStrings(`
+ratio = 0.75
+invalid = abc
+[http]
+timeout = 1m30s
+max_body = 10MB
+created = 2023-01-02T15:04:05Z
+day = 2023-01-02
+`)
+	is.NoErr(err)
+	conf := ini.Default()
+
+	// float
+	is.Eq(0.75, conf.Float64("ratio"))
+	is.Eq(0.75, ini.Float64("ratio", 1.5))
+	is.Eq(1.5, ini.Float64("not-exist", 1.5))
+	is.Eq(float64(0), ini.Float64("not-exist"))
+	is.Eq(1.5, conf.Float64("invalid", 1.5))
+	is.Err(conf.Error())
+
+	// duration
+	is.Eq(90*time.Second, conf.Duration("http.timeout"))
+	is.Eq(90*time.Second, ini.Duration("http.timeout", time.Second))
+	is.Eq(time.Second, ini.Duration("not-exist", time.Second))
+	is.Eq(time.Duration(0), ini.Duration("invalid"))
+
+	// byte size
+	is.Eq(uint64(10<<20), conf.ByteSize("http.max_body"))
+	is.Eq(uint64(10<<20), ini.ByteSize("http.max_body", 1024))
+	is.Eq(uint64(1024), ini.ByteSize("not-exist", 1024))
+	is.Eq(uint64(0), ini.ByteSize("invalid"))
+
+	// time
+	tv := conf.Time("http.created", "")
+	is.Eq(2023, tv.Year())
+	tv = ini.Time("http.day", "2006-01-02")
+	is.Eq(2, tv.Day())
+
+	def := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	is.Eq(def, ini.Time("not-exist", "", def))
+	is.True(ini.Time("invalid", "").IsZero())
+}
+
+// level implements encoding.TextUnmarshaler and encoding.TextMarshaler
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+	return nil
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	if l == 1 {
+		return []byte("debug"), nil
+	}
+	return []byte("info"), nil
+}
+
+func TestIni_MapStruct_decodeHooks(t *testing.T) {
+	is := assert.New(t)
+
+	type Server struct {
+		IP      net.IP         `ini:"ip"`
+		Home    url.URL        `ini:"home"`
+		Pattern *regexp.Regexp `ini:"pattern"`
+		Timeout time.Duration  `ini:"timeout"`
+		Created time.Time      `ini:"created"`
+		Day     time.Time      `ini:"day"`
+		Level   level          `ini:"level"`
+		Tags    []string       `ini:"tags"`
+		Ports   []int          `ini:"ports"`
+		Hosts   []string       `ini:"hosts"`
+	}
+
+	conf := ini.New()
+	err := conf.LoadStrings(`
+[server]
+ip = 127.0.0.1
+home = https://github.com/gookit/ini
+pattern = ^\w+$
+timeout = 1m30s
+created = 2024-01-02 03:04:05
+day = 2024-01-02
+level = debug
+tags = a, b ,c
+ports = 80,443
+hosts[] = h1
+hosts[] = h2
+`)
+	is.NoErr(err)
+
+	s := &Server{}
+	is.NoErr(conf.MapStruct("server", s))
+	is.Eq("127.0.0.1", s.IP.String())
+	is.Eq("github.com", s.Home.Host)
+	is.True(s.Pattern.MatchString("abc"))
+	is.Eq(90*time.Second, s.Timeout)
+	is.Eq(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), s.Created)
+	is.Eq(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), s.Day)
+	is.Eq(level(1), s.Level)
+	is.Eq([]string{"a", "b", "c"}, s.Tags)
+	is.Eq([]int{80, 443}, s.Ports)
+	is.Eq([]string{"h1", "h2"}, s.Hosts)
+
+	// encode then decode
+	bs, err := parser.EncodeWith(struct {
+		Server *Server `ini:"server"`
+	}{Server: s}, nil)
+	is.NoErr(err)
+	is.StrContains(string(bs), "level = debug")
+	is.StrContains(string(bs), "created = 2024-01-02T03:04:05Z")
+
+	s2 := &Server{}
+	is.NoErr(parser.Decode(bs, &struct {
+		Server *Server `ini:"server"`
+	}{Server: s2}))
+	is.Eq(s.IP.String(), s2.IP.String())
+	is.Eq(s.Timeout, s2.Timeout)
+	is.Eq(s.Created, s2.Created)
+	is.Eq(s.Level, s2.Level)
+	is.Eq(s.Ports, s2.Ports)
+
+	// error
+	is.NoErr(conf.Set("level", "invalid", "server"))
+	is.ErrSubMsg(conf.MapStruct("server", s), `invalid level "invalid"`)
+}
+
+func TestIni_MapStruct_defaultRequired(t *testing.T) {
+	is := assert.New(t)
+
+	type Replica struct {
+		Host string `ini:"host" required:"true"`
+		Port int    `ini:"port" default:"3306"`
+	}
+	type Db struct {
+		Host    string        `ini:"host" required:"true"`
+		Port    int           `ini:"port" default:"3306"`
+		User    string        `ini:"user" required:"true"`
+		Timeout time.Duration `ini:"timeout" default:"5s"`
+		Tags    []string      `ini:"tags" default:"a, b"`
+		Replica Replica       `ini:"replica"`
+	}
+
+	conf := ini.New()
+	err := conf.LoadStrings(`
+[db]
+host = localhost
+user =
+`)
+	is.NoErr(err)
+
+	db := &Db{}
+	err = conf.MapStruct("db", db)
+	is.ErrMsg(err, "ini: missing required keys: db.user, db.replica.host")
+
+	var reqErr *ini.RequiredError
+	is.True(errors.As(err, &reqErr))
+	is.Eq("db", reqErr.Section)
+	is.Eq([]string{"user", "replica.host"}, reqErr.Keys)
+
+	// defaults are applied
+	is.Eq("localhost", db.Host)
+	is.Eq(3306, db.Port)
+	is.Eq(5*time.Second, db.Timeout)
+	is.Eq([]string{"a", "b"}, db.Tags)
+	is.Eq(3306, db.Replica.Port)
+
+	// the existing value will not be overwritten
+	is.NoErr(conf.Set("port", "3307", "db"))
+	is.NoErr(conf.Set("user", "admin", "db"))
+	db = &Db{}
+	err = conf.MapStruct("db", db)
+	is.ErrMsg(err, "ini: missing required keys: db.replica.host")
+	is.Eq(3307, db.Port)
+	is.Eq("admin", db.User)
+
+	// bind all data
+	type App struct {
+		Name string `ini:"name" required:"true"`
+		Env  string `ini:"env" default:"dev"`
+		Db   Db     `ini:"db"`
+	}
+
+	app := &App{}
+	err = conf.Decode(app)
+	is.ErrMsg(err, "ini: missing required keys: name, db.replica.host")
+	is.Eq("dev", app.Env)
+	is.Eq(3307, app.Db.Port)
+}
+
+func TestIni_MapStruct_recursiveType(t *testing.T) {
+	is := assert.New(t)
+
+	type Node struct {
+		Name  string `ini:"name" default:"node"`
+		Child *Node  `ini:"child"`
+	}
+
+	conf := ini.NewWithOptions(ini.NestedSection)
+	is.NoErr(conf.LoadStrings("[n]\nname = root\n[n.child]\nname = leaf\n[empty]\nkey = val"))
+
+	n := &Node{}
+	is.NoErr(conf.MapStruct("n", n))
+	is.Eq("root", n.Name)
+	is.NotNil(n.Child)
+	is.Eq("leaf", n.Child.Name)
+	is.Nil(n.Child.Child)
+
+	// the recursive type without data
+	n = &Node{}
+	is.NoErr(conf.MapStruct("empty", n))
+	is.Eq("node", n.Name)
+	is.Nil(n.Child)
+}
+
+func TestIni_Decode_parseVar(t *testing.T) {
+	is := assert.New(t)
+
+	type Config struct {
+		Name string `ini:"name"`
+		Db   struct {
+			Host string `ini:"host"`
+			Pass string `ini:"pass"`
+		} `ini:"db"`
+	}
+
+	conf := ini.NewWithOptions(ini.ParseVar, ini.ParseResolver)
+	is.NoErr(conf.LoadStrings(`
+name = ${base64:aW5oZXJl}
+host = 10.0.0.1
+[db]
+host = %(host)s
+pass = ${base64:czNjcmV0}
+`))
+
+	cfg := &Config{}
+	is.NoErr(conf.Decode(cfg))
+	is.Eq("inhere", cfg.Name)
+	is.Eq("10.0.0.1", cfg.Db.Host)
+	is.Eq("s3cret", cfg.Db.Pass)
+}
+
+func TestIni_Validate(t *testing.T) {
+	is := assert.New(t)
+
+	type Db struct {
+		Host    string        `ini:"host" required:"true"`
+		Port    int           `ini:"port"`
+		Timeout time.Duration `ini:"timeout"`
+		Labels  map[string]string
+	}
+	type Config struct {
+		Name  string `ini:"name"`
+		Debug bool   `ini:"debug"`
+		Db    Db     `ini:"db"`
+	}
+
+	conf := ini.New()
+	is.NoErr(conf.LoadStrings(`
+name = app
+debug = true
+[db]
+host = localhost
+port = 3306
+timeout = 3s
+labels[env] = dev
+`))
+
+	cfg := &Config{}
+	is.NoErr(conf.Validate(cfg))
+	is.Eq("", cfg.Name)
+
+	// has problems
+	is.NoErr(conf.LoadStrings(`
+nmae = typo
+debug = not-bool
+[db]
+hostt = typo
+host =
+port = abc
+[cache]
+size = 10
+`))
+
+	err := conf.Validate(cfg)
+	var ve *ini.ValidateError
+	is.True(errors.As(err, &ve))
+	is.Eq([]string{"cache", "db.hostt", "nmae"}, ve.Unknown)
+	is.Eq([]string{"db.host"}, ve.Missing)
+	is.Len(ve.Invalid, 2)
+	is.StrContains(ve.Invalid[0].Error(), "'db.port' cannot parse value")
+	is.StrContains(ve.Invalid[1].Error(), "'debug' cannot parse value")
+	is.StrContains(err.Error(), "ini: validate failed, 6 problem(s):\n  unknown key \"cache\"")
+	is.Eq("", cfg.Name)
+
+	is.ErrMsg(conf.Validate(cfg.Name), "ini: validate target must be a pointer to struct")
+}
+
+func TestIni_concurrent(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.ParseVar, ini.KeepOrder)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			si := strconv.Itoa(i)
+			for j := 0; j < 50; j++ {
+				_ = conf.LoadStrings("name = app\n[db]\nhost = local\nurl = %(host)s:" + si)
+				_ = conf.Set("key"+si, j, "sec"+si)
+				_ = conf.SetSection("sec"+si, map[string]string{"a": si})
+				_ = conf.NewSection("new"+si, map[string]string{"b": si})
+				conf.Get("db.url")
+				conf.Int("sec" + si + ".key" + si)
+				conf.Slice("db.host")
+				conf.StringMap("db")["url"] = "changed"
+				conf.Data()["db"]["host"] = "changed"
+				conf.SectionNames()
+				conf.Keys("db")
+				conf.PrettyJSON()
+				conf.Delete("sec" + si + ".a")
+				conf.DelSection("new" + si)
+				_ = conf.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	is.Eq("local", conf.Get("db.host"))
+	is.Eq("local:", conf.Get("db.url")[:6])
+
+	// the returned and loaded maps are copies
+	mp := map[string]ini.Section{"sec": {"key": "val"}}
+	conf = ini.New()
+	is.NoErr(conf.LoadData(mp))
+	mp["sec"]["key"] = "new"
+	conf.Data()["sec"]["key"] = "new"
+	conf.StringMap("sec")["key"] = "new"
+	is.Eq("val", conf.Get("sec.key"))
+
+	conf.Reset()
+	is.True(conf.IsEmpty())
+}
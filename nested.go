@@ -0,0 +1,100 @@
+package ini
+
+import (
+	"sort"
+	"strings"
+)
+
+// split key by the longest matched section name. eg: "db.primary.host" => "db.primary", "host"
+func (c *Ini) splitNestedKey(key string) (string, string, bool) {
+	sep := c.opts.SectionSep
+	for pos := strings.LastIndex(key, sep); pos > 0; pos = strings.LastIndex(key[:pos], sep) {
+		name := strings.TrimSpace(key[:pos])
+		if c.hasSection(name) {
+			return name, strings.TrimSpace(key[pos+len(sep):]), true
+		}
+	}
+	return "", "", false
+}
+
+func (c *Ini) hasSection(name string) bool {
+	if _, ok := c.data[name]; ok {
+		return true
+	}
+	_, ok := c.arrays[name]
+	return ok
+}
+
+// ChildSections get direct child section names of the section, on Options.NestedSection=true.
+//
+// eg: section "db" has children "db.primary", "db.replica"
+func ChildSections(name string) []string { return dc.ChildSections(name) }
+
+// ChildSections get direct child section names of the section, on Options.NestedSection=true.
+//
+// eg: section "db" has children "db.primary", "db.replica"
+func (c *Ini) ChildSections(name string) (ls []string) {
+	c.rLock()
+	defer c.rUnlock()
+
+	prefix := c.formatKey(name) + c.opts.SectionSep
+	for _, sec := range c.sectionNames() {
+		if strings.HasPrefix(sec, prefix) && !strings.Contains(sec[len(prefix):], c.opts.SectionSep) {
+			ls = append(ls, sec)
+		}
+	}
+	return
+}
+
+// nestedData build nested map data by the section path.
+// if prefix is not empty, only collect the section and its children sections.
+func (c *Ini) nestedData(prefix string) map[string]any {
+	names := make([]string, 0, len(c.data)+len(c.arrays))
+	for name := range c.data {
+		names = append(names, name)
+	}
+	for name := range c.arrays {
+		if _, ok := c.data[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	// parent sections will be handled before children
+	sort.Strings(names)
+
+	sep := c.opts.SectionSep
+	tree := make(map[string]any, len(names))
+	for _, name := range names {
+		path := name
+		if prefix != "" {
+			if name == prefix {
+				path = ""
+			} else if strings.HasPrefix(name, prefix+sep) {
+				path = name[len(prefix)+len(sep):]
+			} else {
+				continue
+			}
+		}
+
+		node := tree
+		if path != "" {
+			for _, part := range strings.Split(path, sep) {
+				child, ok := node[part].(map[string]any)
+				if !ok {
+					child = make(map[string]any)
+					node[part] = child
+				}
+				node = child
+			}
+		}
+
+		// the values are resolved as same as Get()
+		for key, val := range c.sectionAnyMap(name, c.stringMap(name), true) {
+			// keep the child section on conflict
+			if _, ok := node[key].(map[string]any); !ok {
+				node[key] = val
+			}
+		}
+	}
+	return tree
+}
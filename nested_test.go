@@ -0,0 +1,113 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+var nestedIniStr = `
+name = app
+[database]
+driver = mysql
+[database.primary]
+host = 10.0.0.1
+port = 3306
+[database.replica]
+host = 10.0.0.2
+[database.replica.backup]
+host = 10.0.0.3
+`
+
+func TestIni_NestedSection(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.NestedSection)
+	is.NoErr(conf.LoadStrings(nestedIniStr))
+	is.True(conf.Options().NestedSection)
+
+	is.Eq("app", conf.Get("name"))
+	is.Eq("mysql", conf.Get("database.driver"))
+	is.Eq("10.0.0.1", conf.Get("database.primary.host"))
+	is.Eq(3306, conf.Int("database.primary.port"))
+	is.Eq("10.0.0.3", conf.Get("database.replica.backup.host"))
+	is.Eq("", conf.Get("database.other.host"))
+	is.False(conf.HasKey("database.primary.notExist"))
+
+	is.Eq([]string{"database.primary", "database.replica"}, conf.ChildSections("database"))
+	is.Empty(conf.ChildSections("database.primary"))
+
+	is.True(conf.Delete("database.replica.host"))
+	is.False(conf.HasKey("database.replica.host"))
+
+	type Server struct {
+		Host string
+		Port int
+	}
+	type Replica struct {
+		Host   string
+		Backup Server
+	}
+	type Database struct {
+		Driver  string
+		Primary Server
+		Replica Replica
+	}
+
+	db := &Database{}
+	is.NoErr(conf.MapStruct("database", db))
+	is.Eq("mysql", db.Driver)
+	is.Eq("10.0.0.1", db.Primary.Host)
+	is.Eq(3306, db.Primary.Port)
+	is.Eq("10.0.0.3", db.Replica.Backup.Host)
+
+	srv := &Server{}
+	is.NoErr(conf.MapStruct("database.primary", srv))
+	is.Eq("10.0.0.1", srv.Host)
+	is.Err(conf.MapStruct("not-exist", srv))
+
+	cfg := &struct {
+		Name     string
+		Database Database
+	}{}
+	is.NoErr(conf.Decode(cfg))
+	is.Eq("app", cfg.Name)
+	is.Eq("10.0.0.1", cfg.Database.Primary.Host)
+	is.Eq("10.0.0.3", cfg.Database.Replica.Backup.Host)
+
+	// not nested mode
+	conf = ini.New()
+	is.NoErr(conf.LoadStrings(nestedIniStr))
+	is.Eq("", conf.Get("database.primary.host"))
+}
+
+func TestIni_NestedSection_parseVar(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.NestedSection, ini.ParseVar, ini.ParseResolver)
+	is.NoErr(conf.LoadStrings(`
+host = 10.0.0.1
+[database]
+name = ${base64:aW5oZXJl}
+[database.primary]
+host = %(host)s
+`))
+
+	type Database struct {
+		Name    string
+		Primary struct {
+			Host string
+		}
+	}
+
+	db := &Database{}
+	is.NoErr(conf.MapStruct("database", db))
+	is.Eq("inhere", db.Name)
+	is.Eq("10.0.0.1", db.Primary.Host)
+}
+
+func TestChildSections(t *testing.T) {
+	defer ini.ResetStd()
+	ini.WithOptions(ini.NestedSection)
+	assert.NoErr(t, ini.LoadStrings(nestedIniStr))
+	assert.Eq(t, []string{"database.replica.backup"}, ini.ChildSections("database.replica"))
+}
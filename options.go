@@ -10,11 +10,60 @@ type Options struct {
 	TagName string
 	// ParseEnv parse ENV var name. default True
 	ParseEnv bool
+	// EnvOverlay override the values by ENV on lookup, need ParseEnv=true. see WithEnvOverlay()
+	//
+	// eg: with the prefix "APP_", the "APP_DB__HOST" will override "db.host"
+	EnvOverlay *EnvOverlay
 	// ParseVar parse variable reference "%(varName)s". default False
 	ParseVar bool
 	// ReplaceNl replace the "\n" to newline
 	ReplaceNl bool
+	// ParseInherit parse section inheritance declaration. eg: [child : parent]. default False
+	//
+	// Lookup the key in the child section will fall back to the parent section.
+	ParseInherit bool
+	// KeepOrder keep the loaded/set order of sections and keys,
+	// use for ordered iteration and export. default False
+	//
+	// The order is available by Ini.SectionNames(), Ini.Keys(), WriteTo and PrettyJSON.
+	// Section.Keys() still returns sorted keys, since the Section is a plain map.
+	KeepOrder bool
+	// KeepDocument retain the parsed document(comments, blank lines, quoting and ordering),
+	// WriteTo will write back the document with minimal changes. default False
+	//
+	// With ParseInclude, only the document of the including file is retained, the include directives are kept.
+	KeepDocument bool
+	// IndentContinue allow indented continuation lines for multi line value, like Python configparser. default False
+	IndentContinue bool
+	// DupPolicy policy of the duplicate keys in a section on parse. default is parser.DupLastWins
+	//
+	// NOTE: the values loaded by multi files/strings still be merged by override.
+	DupPolicy parser.DupPolicy
+	// StrictParse strict mode for parse, unrecognized lines, duplicate keys and
+	// empty section names will return *parser.SyntaxError. default False
+	StrictParse bool
+	// ParseInclude parse include directive on load files. default False
+	//
+	// eg: "include = other.ini", "!include conf.d/*.ini".
+	// The relative path will be resolved by the dir of the including file.
+	ParseInclude bool
+	// MaxIncludeDepth max depth of the include files. default is DefIncludeDepth
+	MaxIncludeDepth int
 
+	// ExtendedVar parse the extended variable reference "${key}", "${section:key}"
+	// on ParseVar=true, like Python configparser.ExtendedInterpolation. default False
+	//
+	// The "${NAME}" will fall back to the ENV value on ParseEnv=true.
+	ExtendedVar bool
+	// StrictVar return error on the variable reference cannot be resolved. default False
+	StrictVar bool
+	// ParseResolver resolve the expressions like "${scheme:arg}" by the registered resolvers on get value.
+	// eg: "${env:HOME}", "${file:/run/secrets/db_pass}". default False
+	//
+	// see Ini.RegisterResolver() for register custom resolver.
+	ParseResolver bool
+	// MaxVarDepth max depth of the nested variable references. default is DefVarDepth
+	MaxVarDepth int
 	// VarOpen var left open char. default "%("
 	VarOpen string
 	// VarClose var right close char. default ")s"
@@ -26,6 +75,18 @@ type Options struct {
 	DefSection string
 	// SectionSep sep char for split key path. default ".", use like "section.subKey"
 	SectionSep string
+	// NestedSection treat section name as a path by SectionSep. eg: [db.primary]
+	//
+	// - Get("db.primary.host") will find value from the longest matched section.
+	// - MapStruct/Decode will bind nested sections to nested struct.
+	NestedSection bool
+	// Layered each Load* call creates a named layer instead of merge to the data. default False
+	//
+	// - the later loaded layer has higher priority, see Ini.SetLayer() for add layer with priority.
+	// - Origin(key) reports which layer supplied the effective value.
+	// - Set, SetSection, NewSection will write to the LayerRuntime layer. Delete will remove the key from all layers.
+	// - the KeepDocument is not supported in the layered mode.
+	Layered bool
 }
 
 // newDefaultOptions create a new default Options
@@ -43,6 +104,9 @@ func newDefaultOptions() *Options {
 
 		DefSection: parser.DefSection,
 		SectionSep: SepSection,
+
+		MaxIncludeDepth: DefIncludeDepth,
+		MaxVarDepth:     DefVarDepth,
 	}
 }
 
@@ -60,6 +124,31 @@ func Readonly(opts *Options) { opts.Readonly = true }
 //	ini.NewWithOptions(ini.ParseVar)
 func ParseVar(opts *Options) { opts.ParseVar = true }
 
+// ExtendedVar will parse the extended variable reference "${key}", "${section:key}",
+// it will enable ParseVar too.
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.ExtendedVar)
+func ExtendedVar(opts *Options) {
+	opts.ParseVar = true
+	opts.ExtendedVar = true
+}
+
+// StrictVar will return error on the variable reference cannot be resolved.
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.ParseVar, ini.StrictVar)
+func StrictVar(opts *Options) { opts.StrictVar = true }
+
+// ParseResolver will resolve the expressions like "${env:HOME}" on get value
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.ParseResolver)
+func ParseResolver(opts *Options) { opts.ParseResolver = true }
+
 // ParseEnv will parse ENV key on get value
 //
 // Usage:
@@ -67,8 +156,75 @@ func ParseVar(opts *Options) { opts.ParseVar = true }
 //	ini.NewWithOptions(ini.ParseEnv)
 func ParseEnv(opts *Options) { opts.ParseEnv = true }
 
+// ParseInherit will parse section inheritance. eg: [child : parent]
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.ParseInherit)
+func ParseInherit(opts *Options) { opts.ParseInherit = true }
+
+// IndentContinue for parse multi line value
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.IndentContinue)
+func IndentContinue(opts *Options) { opts.IndentContinue = true }
+
+// WithDupPolicy set the policy of the duplicate keys on parse
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.WithDupPolicy(parser.DupError))
+func WithDupPolicy(policy parser.DupPolicy) func(*Options) {
+	return func(opts *Options) {
+		opts.DupPolicy = policy
+	}
+}
+
+// StrictParse for parse contents
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.StrictParse)
+func StrictParse(opts *Options) { opts.StrictParse = true }
+
+// ParseInclude will parse include directive on load files.
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.ParseInclude)
+func ParseInclude(opts *Options) { opts.ParseInclude = true }
+
 // IgnoreCase for get/set value by key
 func IgnoreCase(opts *Options) { opts.IgnoreCase = true }
 
 // ReplaceNl for parse
 func ReplaceNl(opts *Options) { opts.ReplaceNl = true }
+
+// NestedSection for treat section name as a path. eg: [db.primary]
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.NestedSection)
+func NestedSection(opts *Options) { opts.NestedSection = true }
+
+// KeepOrder of sections and keys. get ordered keys by Ini.Keys(section), not Section.Keys()
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.KeepOrder)
+func KeepOrder(opts *Options) { opts.KeepOrder = true }
+
+// KeepDocument for round-trip edit and write back
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.KeepDocument)
+func KeepDocument(opts *Options) { opts.KeepDocument = true }
+
+// Layered each Load* call creates a named layer, lookups walk layers by priority
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.Layered)
+func Layered(opts *Options) { opts.Layered = true }
@@ -0,0 +1,182 @@
+package ini
+
+import "sort"
+
+// keyOrder records the insertion order of sections and keys.
+type keyOrder struct {
+	sections []string
+	// keys of each section, key is section name
+	keys map[string][]string
+	// index for check exists. key is section name
+	index map[string]map[string]bool
+}
+
+func newKeyOrder() *keyOrder {
+	return &keyOrder{
+		keys:  make(map[string][]string),
+		index: make(map[string]map[string]bool),
+	}
+}
+
+// addSection record section if not exists
+func (o *keyOrder) addSection(section string) {
+	if _, ok := o.index[section]; !ok {
+		o.index[section] = make(map[string]bool)
+		o.sections = append(o.sections, section)
+	}
+}
+
+// add record section and key if not exists
+func (o *keyOrder) add(section, key string) {
+	o.addSection(section)
+	if !o.index[section][key] {
+		o.index[section][key] = true
+		o.keys[section] = append(o.keys[section], key)
+	}
+}
+
+// addMap record section and keys of the map, keys will be sorted.
+func (o *keyOrder) addMap(section string, mp map[string]string) {
+	o.addSection(section)
+	for _, key := range sortedKeys(mp) {
+		o.add(section, key)
+	}
+}
+
+func (o *keyOrder) delKey(section, key string) {
+	if !o.index[section][key] {
+		return
+	}
+
+	delete(o.index[section], key)
+	o.keys[section] = removeString(o.keys[section], key)
+}
+
+// clearKeys of the section, keep the section position.
+func (o *keyOrder) clearKeys(section string) {
+	if _, ok := o.index[section]; ok {
+		o.keys[section] = nil
+		o.index[section] = make(map[string]bool)
+	}
+}
+
+func (o *keyOrder) delSection(section string) {
+	if _, ok := o.index[section]; !ok {
+		return
+	}
+
+	delete(o.keys, section)
+	delete(o.index, section)
+	o.sections = removeString(o.sections, section)
+}
+
+func (o *keyOrder) clone() *keyOrder {
+	no := &keyOrder{
+		sections: append([]string(nil), o.sections...),
+		keys:     make(map[string][]string, len(o.keys)),
+		index:    make(map[string]map[string]bool, len(o.index)),
+	}
+	for section, keys := range o.keys {
+		no.keys[section] = append([]string(nil), keys...)
+	}
+	for section, idx := range o.index {
+		mp := make(map[string]bool, len(idx))
+		for key := range idx {
+			mp[key] = true
+		}
+		no.index[section] = mp
+	}
+	return no
+}
+
+func removeString(ss []string, s string) []string {
+	for i, v := range ss {
+		if v == s {
+			return append(ss[:i:i], ss[i+1:]...)
+		}
+	}
+	return ss
+}
+
+func sortedKeys(mp map[string]string) []string {
+	keys := make([]string, 0, len(mp))
+	for key := range mp {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Keys get all key names of the section, always returns sorted keys.
+//
+// NOTE: the Section is a plain map, it cannot keep the key order even if Options.KeepOrder is true.
+// please use Ini.Keys(section) for get keys by the loaded/set order.
+func (s Section) Keys() []string { return sortedKeys(s) }
+
+// SectionNames get all section names.
+// if Options.KeepOrder is true, returns by the loaded order, otherwise returns sorted names.
+func SectionNames() []string { return dc.SectionNames() }
+
+// SectionNames get all section names.
+// if Options.KeepOrder is true, returns by the loaded order, otherwise returns sorted names.
+func (c *Ini) SectionNames() []string {
+	c.rLock()
+	defer c.rUnlock()
+	return c.sectionNames()
+}
+
+func (c *Ini) sectionNames() []string {
+	if c.order != nil {
+		ss := make([]string, 0, len(c.order.sections))
+		for _, name := range c.order.sections {
+			if _, ok := c.data[name]; ok {
+				ss = append(ss, name)
+			}
+		}
+		return ss
+	}
+
+	ss := make([]string, 0, len(c.data))
+	for name := range c.data {
+		ss = append(ss, name)
+	}
+
+	sort.Strings(ss)
+	return ss
+}
+
+// Keys get all key names of the section.
+// if Options.KeepOrder is true, returns by the loaded order, otherwise returns sorted keys.
+func Keys(section string) []string { return dc.Keys(section) }
+
+// Keys get all key names of the section.
+// if Options.KeepOrder is true, returns by the loaded order, otherwise returns sorted keys.
+func (c *Ini) Keys(section string) []string {
+	c.rLock()
+	defer c.rUnlock()
+	return c.keys(c.formatKey(section))
+}
+
+func (c *Ini) keys(section string) []string {
+	if section == "" {
+		section = c.opts.DefSection
+	}
+
+	sec, ok := c.data[section]
+	if !ok {
+		return nil
+	}
+
+	if c.order == nil {
+		return sec.Keys()
+	}
+
+	keys := make([]string, 0, len(sec))
+	for _, key := range c.order.keys[section] {
+		if _, ok := sec[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,109 @@
+package ini_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_KeepOrder(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.KeepOrder)
+
+	err := conf.LoadStrings(`
+name = inhere
+age = 28
+[zSec]
+key = val
+abc = val1
+[aSec]
+b = 2
+a = 1
+`)
+	is.NoErr(err)
+	is.True(conf.Options().KeepOrder)
+
+	is.Eq([]string{"__default", "zSec", "aSec"}, conf.SectionNames())
+	is.Eq([]string{"zSec", "aSec"}, conf.SectionKeys(false))
+	is.Eq([]string{"name", "age"}, conf.Keys(""))
+	is.Eq([]string{"key", "abc"}, conf.Keys("zSec"))
+	is.Eq([]string{"abc", "key"}, conf.Section("zSec").Keys())
+	is.Nil(conf.Keys("notExist"))
+
+	// set and delete
+	is.NoErr(conf.Set("new", "val", "zSec"))
+	is.NoErr(conf.Set("key", "val2", "zSec"))
+	is.True(conf.Delete("zSec.abc"))
+	is.Eq([]string{"key", "new"}, conf.Keys("zSec"))
+
+	is.NoErr(conf.SetSection("cSec", map[string]string{"y": "1", "x": "2"}))
+	is.NoErr(conf.NewSection("aSec", map[string]string{"c": "3"}))
+	is.True(conf.DelSection("__default"))
+	is.Eq([]string{"zSec", "aSec", "cSec"}, conf.SectionNames())
+	is.Eq([]string{"c"}, conf.Keys("aSec"))
+	is.Eq([]string{"x", "y"}, conf.Keys("cSec"))
+
+	buf := &bytes.Buffer{}
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.StrContains(buf.String(), "[zSec]\nkey = val2\nnew = val\n\n[aSec]\nc = 3\n\n[cSec]\nx = 2\ny = 1\n")
+
+	is.Eq(`{
+    "zSec": {
+        "key": "val2",
+        "new": "val"
+    },
+    "aSec": {
+        "c": "3"
+    },
+    "cSec": {
+        "x": "2",
+        "y": "1"
+    }
+}`, conf.PrettyJSON())
+
+	conf.Reset()
+	is.Empty(conf.SectionNames())
+
+	// load data
+	is.NoErr(conf.LoadData(map[string]ini.Section{"b": {"k": "v"}, "a": {"k": "v"}}))
+	is.Eq([]string{"a", "b"}, conf.SectionNames())
+}
+
+func TestIni_SectionNames(t *testing.T) {
+	defer ini.Reset()
+	err := ini.LoadStrings(`
+name = inhere
+[zSec]
+key = val
+abc = val1
+[aSec]
+k = v
+`)
+	assert.NoErr(t, err)
+	assert.Eq(t, []string{"__default", "aSec", "zSec"}, ini.SectionNames())
+	assert.Eq(t, []string{"abc", "key"}, ini.Keys("zSec"))
+}
+
+func TestIni_KeepOrder_inherit(t *testing.T) {
+	is := assert.New(t)
+	conf := ini.NewWithOptions(ini.KeepOrder, ini.ParseInherit)
+
+	err := conf.LoadStrings(`
+[zSec]
+key = val
+[child : zSec]
+b = 2
+a = 1
+[aSec]
+k = v
+`)
+	is.NoErr(err)
+
+	buf := &bytes.Buffer{}
+	_, err = conf.WriteTo(buf)
+	is.NoErr(err)
+	is.StrContains(buf.String(), "[zSec]\nkey = val\n\n[child : zSec]\nb = 2\na = 1\n\n[aSec]\nk = v\n")
+}
@@ -0,0 +1,141 @@
+package ini
+
+import (
+	"os"
+	"strings"
+)
+
+// LayerEnv the layer name reported by Origin on the value is overridden by the ENV overlay
+const LayerEnv = "env"
+
+// EnvOverlay settings for override the values by ENV on lookup. see WithEnvOverlay()
+type EnvOverlay struct {
+	// Prefix of the ENV name. eg: "APP_"
+	Prefix string
+	// SectionSep separator between the section and key in the ENV name. default "__"
+	SectionSep string
+	// KeySep replace the chars not allowed in ENV name of the section and key. default "_"
+	//
+	// eg: the nested section "db.primary" => "DB_PRIMARY", the key "max-conn" => "MAX_CONN"
+	KeySep string
+	// KeepCase don't convert the section and key to upper case. default False
+	KeepCase bool
+}
+
+// WithEnvOverlay override the values by ENV on lookup, need Options.ParseEnv=true(default is true).
+//
+// With the prefix "APP_", the "APP_DB__HOST" will override "db.host",
+// the "APP_NAME" will override "name" in the default section.
+//
+// Usage:
+//
+//	ini.NewWithOptions(ini.WithEnvOverlay("APP_"))
+//	// custom the separators
+//	ini.NewWithOptions(ini.WithEnvOverlay("APP_", func(eo *ini.EnvOverlay) {
+//		eo.SectionSep = "_"
+//	}))
+func WithEnvOverlay(prefix string, fns ...func(eo *EnvOverlay)) func(*Options) {
+	return func(opts *Options) {
+		eo := &EnvOverlay{Prefix: prefix, SectionSep: "__", KeySep: "_"}
+		for _, fn := range fns {
+			fn(eo)
+		}
+		opts.EnvOverlay = eo
+	}
+}
+
+// EnvOverrides get the keys overridden by ENV of the default instance
+func EnvOverrides() map[string]string { return dc.EnvOverrides() }
+
+// EnvOverrides get the loaded keys overridden by the ENV overlay.
+// key is key path like "db.host", value is the ENV name like "APP_DB__HOST".
+func (c *Ini) EnvOverrides() map[string]string {
+	c.rLock()
+	defer c.rUnlock()
+
+	mp := make(map[string]string)
+	if !c.envOverlay() {
+		return mp
+	}
+
+	add := func(section, key string) {
+		name := c.envName(section, key)
+		if _, ok := os.LookupEnv(name); ok {
+			mp[c.varKeyPath(section, key)] = name
+		}
+	}
+
+	for section, sec := range c.data {
+		for key := range sec {
+			add(section, key)
+		}
+	}
+	for section, arrMp := range c.arrays {
+		for key := range arrMp {
+			add(section, key)
+		}
+	}
+	return mp
+}
+
+// is the ENV overlay enabled
+func (c *Ini) envOverlay() bool {
+	return c.opts.EnvOverlay != nil && c.opts.ParseEnv
+}
+
+// get the value of the key from ENV overlay
+func (c *Ini) envValue(section, key string) (string, bool) {
+	if !c.envOverlay() {
+		return "", false
+	}
+	return os.LookupEnv(c.envName(section, key))
+}
+
+// ENV name of the key. eg: "db", "host" => "APP_DB__HOST"
+func (c *Ini) envName(section, key string) string {
+	eo := c.opts.EnvOverlay
+	if section == c.opts.DefSection {
+		return eo.Prefix + c.envWord(key)
+	}
+	return eo.Prefix + c.envWord(section) + eo.SectionSep + c.envWord(key)
+}
+
+// convert the section or key name to the ENV name part
+func (c *Ini) envWord(name string) string {
+	eo := c.opts.EnvOverlay
+	if !eo.KeepCase {
+		name = strings.ToUpper(name)
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteString(eo.KeySep)
+		}
+	}
+	return sb.String()
+}
+
+// override the values of the section data by ENV, will copy the section on has overridden.
+func (c *Ini) envSection(name string, sec Section) Section {
+	if !c.envOverlay() {
+		return sec
+	}
+
+	var cp Section
+	for key := range sec {
+		if val, ok := c.envValue(name, key); ok {
+			if cp == nil {
+				cp = copySection(sec)
+			}
+			cp[key] = val
+		}
+	}
+
+	if cp == nil {
+		return sec
+	}
+	return cp
+}
@@ -0,0 +1,96 @@
+package ini_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil"
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_WithEnvOverlay(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := ini.NewWithOptions(ini.WithEnvOverlay("APP_"), ini.NestedSection, ini.Layered)
+	is.NoErr(cfg.LoadStrings(`
+name = app
+[db]
+host = localhost
+port = 3306
+max-conn = 10
+tags[] = a
+[db.primary]
+host = primary.local
+`))
+
+	testutil.MockOsEnv(map[string]string{
+		"APP_NAME":              "env-app",
+		"APP_DB__HOST":          "db.env",
+		"APP_DB__MAX_CONN":      "20",
+		"APP_DB__TAGS":          "b,c",
+		"APP_DB_PRIMARY__HOST":  "primary.env",
+		"APP_DB__NOT_IN_CONFIG": "val",
+	}, func() {
+		is.Eq("env-app", cfg.Get("name"))
+		is.Eq("db.env", cfg.Get("db.host"))
+		is.Eq(3306, cfg.Int("db.port"))
+		is.Eq(20, cfg.Int("db.max-conn"))
+		is.Eq([]string{"b", "c"}, cfg.Slice("db.tags"))
+		is.Eq("primary.env", cfg.Get("db.primary.host"))
+		is.Eq("val", cfg.Get("db.not_in_config"))
+		is.Eq("db.env", cfg.StringMap("db")["host"])
+
+		type Db struct {
+			Host    string
+			Port    int
+			Primary struct{ Host string }
+		}
+		db := &Db{}
+		is.NoErr(cfg.MapStruct("db", db))
+		is.Eq("db.env", db.Host)
+		is.Eq(3306, db.Port)
+		is.Eq("primary.env", db.Primary.Host)
+
+		org, ok := cfg.Origin("db.host")
+		is.True(ok)
+		is.Eq(ini.LayerEnv, org.Layer)
+
+		is.Eq(map[string]string{
+			"name":            "APP_NAME",
+			"db.host":         "APP_DB__HOST",
+			"db.max-conn":     "APP_DB__MAX_CONN",
+			"db.tags":         "APP_DB__TAGS",
+			"db.primary.host": "APP_DB_PRIMARY__HOST",
+		}, cfg.EnvOverrides())
+	})
+
+	// the raw data is not modified
+	is.Eq("localhost", cfg.Get("db.host"))
+	is.Eq([]string{"a"}, cfg.Slice("db.tags"))
+	is.Empty(cfg.EnvOverrides())
+}
+
+func TestIni_WithEnvOverlay_options(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := ini.NewWithOptions(ini.WithEnvOverlay("app_", func(eo *ini.EnvOverlay) {
+		eo.SectionSep = "_"
+		eo.KeepCase = true
+	}))
+	is.NoErr(cfg.LoadStrings("[db]\nhost = localhost"))
+
+	testutil.MockOsEnv(map[string]string{
+		"app_db_host": "db.env",
+		"APP_DB_HOST": "upper",
+	}, func() {
+		is.Eq("db.env", cfg.Get("db.host"))
+
+		// disabled by ParseEnv=false
+		cfg = ini.NewWithOptions(ini.WithEnvOverlay("app_"), func(opts *ini.Options) {
+			opts.ParseEnv = false
+		})
+		is.NoErr(cfg.LoadStrings("[db]\nhost = localhost"))
+		is.Eq("localhost", cfg.Get("db.host"))
+		is.Empty(cfg.EnvOverrides())
+	})
+}
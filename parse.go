@@ -3,29 +3,74 @@ package ini
 import (
 	"strings"
 
-	"github.com/gookit/goutil/envutil"
 	"github.com/gookit/ini/v2/parser"
 )
 
-// parse and load ini string
-func (c *Ini) parse(str string) (err error) {
+// parse and load ini string. fromInclude is true on the string is loaded by include directive.
+func (c *Ini) parse(str string, fromInclude bool) (err error) {
 	if strings.TrimSpace(str) == "" {
 		return
 	}
 
-	p := parser.NewLite()
+	// use full mode for collect array values. eg: "key[] = val"
+	p := parser.New(parser.WithParseMode(parser.ModeFull))
 	p.Collector = c.valueCollector
 	p.IgnoreCase = c.opts.IgnoreCase
 	p.DefSection = c.opts.DefSection
+	// the document of the included file will not be retained,
+	// the including file keeps the include directive for write back.
+	p.KeepDocument = c.opts.KeepDocument && !fromInclude
+	p.ParseInherit = c.opts.ParseInherit
+	p.Strict = c.opts.StrictParse
+	p.DupPolicy = c.opts.DupPolicy
+	p.IndentContinue = c.opts.IndentContinue
+	if n := len(c.incChain); n > 0 {
+		p.FileName = c.incChain[n-1]
+	}
+	if c.opts.ParseInclude {
+		p.ParseInclude = true
+		p.Includer = c.loadInclude
+	}
 
 	err = p.ParseString(str)
+	// the position is recorded for each parsed section and key
+	if len(p.Positions()) > 0 {
+		c.changed = true
+	}
 	c.comments = p.Comments()
+	c.addPositions(p.Positions())
+	if err == nil {
+		err = c.collectParents(p.Parents())
+	}
+
+	if doc := p.Document(); doc != nil && err == nil {
+		if c.doc == nil {
+			c.doc = doc
+		} else {
+			c.doc.Merge(doc)
+		}
+	}
+
 	p.Reset()
 	return err
 }
 
+// merge the source positions from parser, keep the first position of the section.
+func (c *Ini) addPositions(positions map[parser.PosKey]parser.Position) {
+	if c.positions == nil {
+		c.positions = make(map[parser.PosKey]parser.Position, len(positions))
+	}
+
+	for name, pos := range positions {
+		if _, ok := c.positions[name]; ok && name.Key == "" {
+			continue
+		}
+		c.positions[name] = pos
+	}
+}
+
 // collect value form parser
-func (c *Ini) valueCollector(section, key, val string, _ bool) {
+func (c *Ini) valueCollector(section, key, val string, isSlice bool) {
 	if c.opts.IgnoreCase {
 		key = strings.ToLower(key)
 		section = strings.ToLower(section)
@@ -33,10 +78,13 @@ func (c *Ini) valueCollector(section, key, val string, _ bool) {
 
 	// backup value on contains var, use for export
 	if strings.ContainsRune(val, '$') {
-		c.rawBak[section+"_"+key] = val
+		if !isSlice {
+			c.rawBak[section+"_"+key] = val
+		}
+
 		// if ParseEnv is true. will parse like: "${SHELL}".
 		if c.opts.ParseEnv {
-			val = envutil.ParseValue(val)
+			val = c.parseEnv(val)
 		}
 	}
 
@@ -44,6 +92,24 @@ func (c *Ini) valueCollector(section, key, val string, _ bool) {
 		val = strings.ReplaceAll(val, `\n`, "\n")
 	}
 
+	if c.order != nil {
+		c.order.add(section, key)
+	}
+
+	// array value. eg: "key[] = val"
+	if isSlice {
+		// has string value, move it to array. eg: collect duplicate keys as array
+		if old, ok := c.data[section][key]; ok {
+			delete(c.data[section], key)
+			c.appendArray(section, key, old)
+		}
+
+		c.appendArray(section, key, val)
+		return
+	}
+
+	// the string value replace the array value
+	c.delArray(section, key)
 	if sec, ok := c.data[section]; ok {
 		sec[key] = val
 		c.data[section] = sec
@@ -52,37 +118,3 @@ func (c *Ini) valueCollector(section, key, val string, _ bool) {
 		c.data[section] = Section{key: val}
 	}
 }
-
-// parse var reference
-func (c *Ini) parseVarReference(key, valStr string, sec Section) string {
-	if c.opts.VarOpen != "" && strings.Index(valStr, c.opts.VarOpen) == -1 {
-		return valStr
-	}
-
-	// http://%(host)s:%(port)s/Portal
-	// %(section:key)s key in the section
-	vars := c.varRegex.FindAllString(valStr, -1)
-	if len(vars) == 0 {
-		return valStr
-	}
-
-	varOLen, varCLen := len(c.opts.VarOpen), len(c.opts.VarClose)
-
-	var name string
-	var oldNew []string
-	for _, fVar := range vars {
-		realVal := fVar
-		name = fVar[varOLen : len(fVar)-varCLen]
-
-		// first, find from current section
-		if val, ok := sec[name]; ok && key != name {
-			realVal = val
-		} else if val, ok = c.getValue(name); ok {
-			realVal = val
-		}
-
-		oldNew = append(oldNew, fVar, realVal)
-	}
-
-	return strings.NewReplacer(oldNew...).Replace(valStr)
-}
@@ -0,0 +1,611 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// NodeKind of the document node
+type NodeKind uint8
+
+// kinds of the document node
+const (
+	NodeBlank NodeKind = iota
+	NodeComment
+	NodeKeyValue
+	// NodeUnknown unrecognized line, will be kept as is
+	NodeUnknown
+)
+
+// DocNode is a logical line in the INI document.
+// A multi line value or multi line comments is one node.
+type DocNode struct {
+	Kind NodeKind
+	// Key name, only for NodeKeyValue
+	Key string
+	// Value of the key, without quotes. only for NodeKeyValue
+	Value string
+
+	// raw text of the node, multi lines are joined by "\n"
+	raw string
+	// text before the value. eg: "key = "
+	prefix string
+	// text after the value. eg: inline comments
+	suffix string
+	// quote char of the value, 0 if value is not quoted
+	quote byte
+}
+
+// Raw text of the node
+func (n *DocNode) Raw() string { return n.raw }
+
+// setValue and re-render raw text, keep the original style.
+func (n *DocNode) setValue(val string) {
+	if n.Value == val && n.raw != "" {
+		return
+	}
+
+	n.Value = val
+	if strings.ContainsRune(val, '\n') {
+		n.raw = n.prefix + formatValue(val)
+		return
+	}
+
+	switch {
+	case n.quote == '"':
+		n.raw = n.prefix + QuoteValue(val) + n.suffix
+	case n.quote == '\'' && !strings.ContainsRune(val, '\''):
+		n.raw = n.prefix + "'" + val + "'" + n.suffix
+	default:
+		n.raw = n.prefix + formatValue(val) + n.suffix
+	}
+}
+
+// DocSection is a section in the INI document
+type DocSection struct {
+	// Name of the section
+	Name string
+	// raw header line. is empty on default section
+	header string
+	// Nodes in the section
+	Nodes []*DocNode
+}
+
+// lastKeyIndex find the last key-value node index, returns -1 on not found
+func (s *DocSection) lastKeyIndex() int {
+	for i := len(s.Nodes) - 1; i >= 0; i-- {
+		if s.Nodes[i].Kind == NodeKeyValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// Document is a round-trip model of INI contents.
+//
+// It retains sections, keys, comments, blank lines, original quoting and
+// ordering, so edit the document and write back will produce a minimal diff.
+type Document struct {
+	// DefSection name of the top section(before any section header)
+	DefSection string
+	// IgnoreCase match section and key name ignore case
+	IgnoreCase bool
+	// Inherit the section header contains inheritance declaration. eg: [child : parent]
+	Inherit bool
+	// IndentContinue the indented lines after key-value line are continuation lines.
+	IndentContinue bool
+	// DupPolicy of the duplicate keys, decide which node will be read and edited.
+	DupPolicy DupPolicy
+	// newline chars of the contents. default is "\n"
+	newline string
+	// contents is end with newline
+	endNl bool
+
+	sections []*DocSection
+}
+
+// NewDocument create an empty document
+func NewDocument(defSection ...string) *Document {
+	doc := &Document{
+		DefSection: DefSection,
+		newline:    "\n",
+		endNl:      true,
+	}
+
+	if len(defSection) > 0 {
+		doc.DefSection = defSection[0]
+	}
+
+	doc.sections = []*DocSection{{Name: doc.DefSection}}
+	return doc
+}
+
+// ParseDocument parse INI contents to a Document.
+// The unrecognized lines will be kept as NodeUnknown.
+func ParseDocument(text string, defSection ...string) *Document {
+	doc := NewDocument(defSection...)
+	doc.parse(text)
+	return doc
+}
+
+// parse contents and append to the document
+func (doc *Document) parse(text string) {
+	if text == "" {
+		return
+	}
+
+	if strings.Contains(text, "\r\n") {
+		doc.newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+
+	doc.endNl = strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	sec := doc.sections[0]
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		str := strings.TrimSpace(line)
+
+		// blank line
+		if str == "" {
+			sec.Nodes = append(sec.Nodes, &DocNode{Kind: NodeBlank, raw: line})
+			continue
+		}
+
+		// comments line
+		if IsCommentChar(str[0]) || strings.HasPrefix(str, "//") {
+			sec.Nodes = append(sec.Nodes, &DocNode{Kind: NodeComment, raw: line})
+			continue
+		}
+
+		// multi line comments. /* ... */
+		if strings.HasPrefix(str, "/*") {
+			end := i
+			for !strings.HasSuffix(strings.TrimSpace(lines[end]), "*/") && end < len(lines)-1 {
+				end++
+			}
+
+			raw := strings.Join(lines[i:end+1], "\n")
+			sec.Nodes = append(sec.Nodes, &DocNode{Kind: NodeComment, raw: raw})
+			i = end
+			continue
+		}
+
+		// section header
+		if matched := sectionRegex.FindStringSubmatch(str); matched != nil {
+			sec = &DocSection{Name: strings.TrimSpace(matched[1]), header: line}
+			doc.sections = append(doc.sections, sec)
+			continue
+		}
+
+		pos := strings.IndexByte(line, '=')
+		if pos < 1 || strings.TrimSpace(line[:pos]) == "" {
+			sec.Nodes = append(sec.Nodes, &DocNode{Kind: NodeUnknown, raw: line})
+			continue
+		}
+
+		node, end := parseDocKeyValue(lines, i, pos, doc.IndentContinue)
+		sec.Nodes = append(sec.Nodes, node)
+		i = end
+	}
+}
+
+// parse key-value node start at lines[idx], returns the node and the end line index.
+func parseDocKeyValue(lines []string, idx, pos int, indent bool) (*DocNode, int) {
+	line := lines[idx]
+	node := &DocNode{
+		Kind: NodeKeyValue,
+		Key:  strings.TrimSpace(line[:pos]),
+		raw:  line,
+	}
+
+	rest := line[pos+1:]
+	val := strings.TrimLeft(rest, " \t")
+	node.prefix = line[:len(line)-len(val)]
+	val = strings.TrimRight(val, " \t")
+
+	// multi line value: """...""" or '''...'''
+	if len(val) >= 3 && (strings.HasPrefix(val, MultiLineMark) || strings.HasPrefix(val, "'''")) {
+		mark := val[:3]
+		body := val[3:]
+		if strings.HasSuffix(body, mark) {
+			node.Value = body[:len(body)-3]
+			return node, idx
+		}
+
+		end := idx
+		values := []string{body}
+		for end < len(lines)-1 {
+			end++
+			str := strings.TrimRight(lines[end], " \t")
+			if strings.HasSuffix(str, mark) {
+				values = append(values, str[:len(str)-3])
+				break
+			}
+			values = append(values, lines[end])
+		}
+
+		node.Value = strings.Join(values, "\n")
+		node.raw = strings.Join(lines[idx:end+1], "\n")
+		return node, end
+	}
+
+	// multi line value ended by "\", the lines are joined without newline
+	if strings.HasSuffix(val, MultiLineMarkQ) {
+		end := idx
+		node.Value = val[:len(val)-1]
+		for end < len(lines)-1 {
+			end++
+			str := strings.TrimSpace(lines[end])
+			if !strings.HasSuffix(str, MultiLineMarkQ) {
+				node.Value += str
+				break
+			}
+			node.Value += str[:len(str)-1]
+		}
+
+		node.raw = strings.Join(lines[idx:end+1], "\n")
+		return node, end
+	}
+
+	// indented continuation lines
+	if indent {
+		width, end := indentWidth(line), idx
+		for end < len(lines)-1 {
+			next := lines[end+1]
+			str := strings.TrimSpace(next)
+			if str == "" || indentWidth(next) <= width {
+				break
+			}
+
+			end++
+			if !IsCommentChar(str[0]) {
+				val += "\n" + str
+			}
+		}
+
+		if end > idx {
+			node.Value = val
+			node.raw = strings.Join(lines[idx:end+1], "\n")
+			return node, end
+		}
+	}
+
+	// quoted value. eg: "val" OR 'val'
+	if len(val) > 1 && (val[0] == '"' || val[0] == '\'') {
+		if str, after, ok := SplitQuoted(val); ok {
+			node.quote = val[0]
+			node.Value = str
+			node.suffix = rest[len(rest)-len(strings.TrimLeft(rest, " \t"))+len(val)-len(after):]
+			return node, idx
+		}
+	}
+
+	node.Value = val
+	node.suffix = rest[len(rest)-len(strings.TrimLeft(rest, " \t"))+len(val):]
+	return node, idx
+}
+
+// MultiLineMark for encode multi line value
+const MultiLineMark = `"""`
+
+/*************************************************************
+ * read document
+ *************************************************************/
+
+// SectionNames get all section names by the order in document.
+func (d *Document) SectionNames() []string {
+	names := make([]string, 0, len(d.sections))
+	for _, sec := range d.sections {
+		if sec.header == "" && len(sec.Nodes) == 0 && sec.Name == d.DefSection {
+			continue
+		}
+		names = append(names, d.secName(sec))
+	}
+	return names
+}
+
+// Section get a section by name. if has multi same name sections, will return last one.
+func (d *Document) Section(name string) *DocSection {
+	for i := len(d.sections) - 1; i >= 0; i-- {
+		if d.sameName(d.secName(d.sections[i]), name) {
+			return d.sections[i]
+		}
+	}
+	return nil
+}
+
+// HasSection check section exists
+func (d *Document) HasSection(name string) bool {
+	return d.Section(name) != nil
+}
+
+// Get value of the key in the section
+func (d *Document) Get(section, key string) (string, bool) {
+	if node := d.findNode(section, key); node != nil {
+		return node.Value, true
+	}
+	return "", false
+}
+
+// Keys get all key names in the section, by the order in document.
+func (d *Document) Keys(section string) (keys []string) {
+	for _, sec := range d.sections {
+		if !d.sameName(d.secName(sec), section) {
+			continue
+		}
+
+		for _, node := range sec.Nodes {
+			if node.Kind == NodeKeyValue {
+				keys = append(keys, node.Key)
+			}
+		}
+	}
+	return
+}
+
+// secName get section name, will remove the inheritance declaration
+func (d *Document) secName(sec *DocSection) string {
+	if d.Inherit {
+		name, _ := SplitInherit(sec.Name)
+		return name
+	}
+	return sec.Name
+}
+
+func (d *Document) sameName(a, b string) bool {
+	if d.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// find the effective key-value node by section and key name.
+//
+// On DupFirstWins and DupCollect, will return the first node, otherwise returns the last node.
+func (d *Document) findNode(section, key string) *DocNode {
+	if d.DupPolicy == DupFirstWins || d.DupPolicy == DupCollect {
+		for _, sec := range d.sections {
+			if !d.sameName(d.secName(sec), section) {
+				continue
+			}
+
+			for _, node := range sec.Nodes {
+				if node.Kind == NodeKeyValue && d.sameName(node.Key, key) {
+					return node
+				}
+			}
+		}
+		return nil
+	}
+
+	for i := len(d.sections) - 1; i >= 0; i-- {
+		sec := d.sections[i]
+		if !d.sameName(d.secName(sec), section) {
+			continue
+		}
+
+		for j := len(sec.Nodes) - 1; j >= 0; j-- {
+			node := sec.Nodes[j]
+			if node.Kind == NodeKeyValue && d.sameName(node.Key, key) {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+/*************************************************************
+ * edit document
+ *************************************************************/
+
+// Set value of the key in the section.
+//
+// If the key exists, only the value will be changed and keep the original style.
+// Otherwise, will append key to the section, the section will be created on not exists.
+//
+// On has duplicate keys, the node is edited by the DupPolicy. On DupCollect, the other nodes will be removed.
+func (d *Document) Set(section, key, val string) {
+	if node := d.findNode(section, key); node != nil {
+		node.setValue(val)
+		if d.DupPolicy == DupCollect {
+			d.deleteNodes(section, key, node)
+		}
+		return
+	}
+	d.add(section, key, val)
+}
+
+// add a new key-value node after the last key of the section.
+// the section will be created on not exists.
+func (d *Document) add(section, key, val string) {
+	sec := d.Section(section)
+	if sec == nil {
+		sec = d.addSection(section)
+	}
+
+	// insert after the last key, use same style as it.
+	idx := sec.lastKeyIndex()
+	prefix := key + " = "
+	if idx > -1 {
+		last := sec.Nodes[idx]
+		prefix = strings.Replace(last.prefix, last.Key, key, 1)
+	}
+
+	node := &DocNode{Kind: NodeKeyValue, Key: key, prefix: prefix}
+	node.setValue(val)
+
+	sec.Nodes = append(sec.Nodes, nil)
+	copy(sec.Nodes[idx+2:], sec.Nodes[idx+1:])
+	sec.Nodes[idx+1] = node
+}
+
+// Delete key from the section, the comments above the key will be removed too.
+//
+// All the duplicate keys will be deleted.
+func (d *Document) Delete(section, key string) bool {
+	return d.deleteNodes(section, key, nil)
+}
+
+// delete the key-value nodes by section and key name, except the keep node.
+func (d *Document) deleteNodes(section, key string, keep *DocNode) (ok bool) {
+	for _, sec := range d.sections {
+		if !d.sameName(d.secName(sec), section) {
+			continue
+		}
+
+		nodes := sec.Nodes[:0]
+		for _, node := range sec.Nodes {
+			if node != keep && node.Kind == NodeKeyValue && d.sameName(node.Key, key) {
+				nodes = trimTailComments(nodes)
+				ok = true
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+		sec.Nodes = nodes
+	}
+	return
+}
+
+// DelSection delete section from the document.
+// The comments above section header will be removed too.
+func (d *Document) DelSection(name string) (ok bool) {
+	secs := d.sections[:0]
+	for i, sec := range d.sections {
+		if !d.sameName(d.secName(sec), name) {
+			secs = append(secs, sec)
+			continue
+		}
+
+		ok = true
+		// default section: only clear nodes
+		if i == 0 && sec.header == "" {
+			sec.Nodes = nil
+			secs = append(secs, sec)
+			continue
+		}
+
+		if len(secs) > 0 {
+			prev := secs[len(secs)-1]
+			prev.Nodes = trimTailComments(prev.Nodes)
+		}
+	}
+
+	d.sections = secs
+	return
+}
+
+// Clone the document, modify the copy will not affect the original document.
+func (d *Document) Clone() *Document {
+	if d == nil {
+		return nil
+	}
+
+	nd := *d
+	nd.sections = make([]*DocSection, len(d.sections))
+	for i, sec := range d.sections {
+		ns := *sec
+		ns.Nodes = make([]*DocNode, len(sec.Nodes))
+		for j, node := range sec.Nodes {
+			nn := *node
+			ns.Nodes[j] = &nn
+		}
+		nd.sections[i] = &ns
+	}
+	return &nd
+}
+
+// Merge other document values to current document.
+//
+// The array values(eg: "key[] = val") will be appended, a string value will replace the array value, and vice versa.
+func (d *Document) Merge(other *Document) {
+	if other == nil {
+		return
+	}
+
+	for _, sec := range other.sections {
+		name := other.secName(sec)
+		for _, node := range sec.Nodes {
+			if node.Kind != NodeKeyValue {
+				continue
+			}
+
+			if key := strings.TrimSuffix(node.Key, "[]"); key != node.Key {
+				d.Delete(name, key)
+				d.add(name, node.Key, node.Value)
+			} else {
+				d.Delete(name, key+"[]")
+				d.Set(name, key, node.Value)
+			}
+		}
+	}
+}
+
+func (d *Document) addSection(name string) *DocSection {
+	if name == d.DefSection {
+		return d.sections[0]
+	}
+
+	// add a blank line before new section
+	last := d.sections[len(d.sections)-1]
+	if ln := len(last.Nodes); ln > 0 || last.header != "" {
+		if ln == 0 || last.Nodes[ln-1].Kind != NodeBlank {
+			last.Nodes = append(last.Nodes, &DocNode{Kind: NodeBlank})
+		}
+	}
+
+	sec := &DocSection{Name: name, header: "[" + name + "]"}
+	d.sections = append(d.sections, sec)
+	return sec
+}
+
+// remove comments at the tail of nodes
+func trimTailComments(nodes []*DocNode) []*DocNode {
+	for len(nodes) > 0 && nodes[len(nodes)-1].Kind == NodeComment {
+		nodes = nodes[:len(nodes)-1]
+	}
+	return nodes
+}
+
+/*************************************************************
+ * export document
+ *************************************************************/
+
+// String of the document
+func (d *Document) String() string {
+	lines := make([]string, 0, len(d.sections)*4)
+	for _, sec := range d.sections {
+		if sec.header != "" {
+			lines = append(lines, sec.header)
+		}
+
+		for _, node := range sec.Nodes {
+			lines = append(lines, node.raw)
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	str := strings.Join(lines, "\n")
+	if d.endNl {
+		str += "\n"
+	}
+
+	if d.newline != "\n" {
+		str = strings.ReplaceAll(str, "\n", d.newline)
+	}
+	return str
+}
+
+// Bytes of the document
+func (d *Document) Bytes() []byte { return []byte(d.String()) }
+
+// WriteTo write the document contents to a writer
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, d.String())
+	return int64(n), err
+}
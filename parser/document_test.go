@@ -0,0 +1,194 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2/parser"
+)
+
+var docStr = `# comments in first line
+name = inhere
+age=28
+hasQuota1 = 'this is val'
+hasQuota2 = "this is val1" # comments for hq2
+desc = """multi line
+value for desc
+"""
+
+; comments for sec1
+[sec1]
+key = val0
+; comments for some
+some = value
+
+[sec2]
+k = v
+`
+
+func TestParseDocument(t *testing.T) {
+	doc := parser.ParseDocument(docStr)
+	assert.Eq(t, docStr, doc.String())
+	assert.Eq(t, []string{parser.DefSection, "sec1", "sec2"}, doc.SectionNames())
+	assert.Eq(t, []string{"name", "age", "hasQuota1", "hasQuota2", "desc"}, doc.Keys(parser.DefSection))
+	assert.True(t, doc.HasSection("sec1"))
+	assert.False(t, doc.HasSection("not-exist"))
+
+	val, ok := doc.Get(parser.DefSection, "hasQuota2")
+	assert.True(t, ok)
+	assert.Eq(t, "this is val1", val)
+
+	val, ok = doc.Get(parser.DefSection, "desc")
+	assert.True(t, ok)
+	assert.Eq(t, "multi line\nvalue for desc\n", val)
+
+	_, ok = doc.Get("sec1", "not-exist")
+	assert.False(t, ok)
+
+	// CRLF
+	doc = parser.ParseDocument("a = b\r\n[sec]\r\nc = d\r\n")
+	doc.Set("sec", "c", "e")
+	assert.Eq(t, "a = b\r\n[sec]\r\nc = e\r\n", doc.String())
+
+	// empty
+	doc = parser.ParseDocument("")
+	assert.Eq(t, "", doc.String())
+	assert.Empty(t, doc.SectionNames())
+}
+
+func TestDocument_edit(t *testing.T) {
+	doc := parser.ParseDocument(docStr)
+
+	// keep style on change value
+	doc.Set(parser.DefSection, "age", "30")
+	doc.Set(parser.DefSection, "hasQuota1", "new val")
+	doc.Set(parser.DefSection, "hasQuota2", "new val1")
+	doc.Set("sec1", "some", "new value")
+	// add new key and section
+	doc.Set(parser.DefSection, "newKey", "val")
+	doc.Set("sec1", "key1", "val1")
+	doc.Set("sec3", "k", "v")
+
+	assert.True(t, doc.Delete("sec2", "k"))
+	assert.False(t, doc.Delete("sec2", "not-exist"))
+
+	assert.Eq(t, `# comments in first line
+name = inhere
+age=30
+hasQuota1 = 'new val'
+hasQuota2 = "new val1" # comments for hq2
+desc = """multi line
+value for desc
+"""
+newKey = val
+
+; comments for sec1
+[sec1]
+key = val0
+; comments for some
+some = new value
+key1 = val1
+
+[sec2]
+
+[sec3]
+k = v
+`, doc.String())
+
+	// delete key will remove comments above it
+	assert.True(t, doc.Delete("sec1", "some"))
+	assert.True(t, doc.DelSection("sec1"))
+	assert.False(t, doc.DelSection("sec1"))
+	assert.Eq(t, `# comments in first line
+name = inhere
+age=30
+hasQuota1 = 'new val'
+hasQuota2 = "new val1" # comments for hq2
+desc = """multi line
+value for desc
+"""
+newKey = val
+
+[sec2]
+
+[sec3]
+k = v
+`, doc.String())
+
+	// multi line value
+	doc = parser.ParseDocument("key = val\n")
+	doc.Set(parser.DefSection, "key", "line1\nline2")
+	assert.Eq(t, "key = \"\"\"line1\nline2\"\"\"\n", doc.String())
+
+	// merge
+	doc = parser.ParseDocument("key = val\n")
+	doc.Merge(parser.ParseDocument("key = val1\n[sec]\nk = v"))
+	assert.Eq(t, "key = val1\n\n[sec]\nk = v\n", doc.String())
+
+	// clone
+	nd := doc.Clone()
+	nd.Set("sec", "k", "v1")
+	nd.Set("sec2", "k", "v")
+	assert.Eq(t, "key = val1\n\n[sec]\nk = v\n", doc.String())
+	assert.Eq(t, "key = val1\n\n[sec]\nk = v1\n\n[sec2]\nk = v\n", nd.String())
+
+	doc = nil
+	assert.Nil(t, doc.Clone())
+}
+
+func TestParser_KeepDocument(t *testing.T) {
+	p := parser.NewLite(parser.KeepDocument)
+	assert.NoErr(t, p.ParseString(docStr))
+	assert.NotNil(t, p.Document())
+	assert.Eq(t, docStr, p.Document().String())
+
+	p.Reset()
+	assert.Nil(t, p.Document())
+
+	assert.NoErr(t, p.ParseBytes([]byte(docStr)))
+	assert.Eq(t, docStr, p.Document().String())
+
+	// not keep
+	p = parser.NewLite()
+	assert.NoErr(t, p.ParseString(docStr))
+	assert.Nil(t, p.Document())
+}
+
+func TestDocument_DupPolicy(t *testing.T) {
+	text := "key = a\nkey = b\n"
+	doc := parser.ParseDocument(text)
+	val, _ := doc.Get(parser.DefSection, "key")
+	assert.Eq(t, "b", val)
+
+	doc = parser.ParseDocument(text)
+	doc.DupPolicy = parser.DupFirstWins
+	val, _ = doc.Get(parser.DefSection, "key")
+	assert.Eq(t, "a", val)
+	doc.Set(parser.DefSection, "key", "c")
+	assert.Eq(t, "key = c\nkey = b\n", doc.String())
+
+	doc = parser.ParseDocument(text)
+	doc.DupPolicy = parser.DupCollect
+	doc.Set(parser.DefSection, "key", "c")
+	assert.Eq(t, "key = c\n", doc.String())
+}
+
+func TestDocument_multiLineValue(t *testing.T) {
+	text := "desc = a \\\n  b\nlist = x\n  y\nname = inhere\n"
+
+	doc := parser.ParseDocument(text)
+	val, _ := doc.Get(parser.DefSection, "desc")
+	assert.Eq(t, "a b", val)
+	assert.Eq(t, text, doc.String())
+
+	p := parser.New(parser.KeepDocument, parser.IndentContinue)
+	assert.NoErr(t, p.ParseString(text))
+	doc = p.Document()
+	val, _ = doc.Get(parser.DefSection, "list")
+	assert.Eq(t, "x\ny", val)
+	assert.Eq(t, []string{"desc", "list", "name"}, doc.Keys(parser.DefSection))
+	assert.Eq(t, text, doc.String())
+
+	doc.Set(parser.DefSection, "list", "x\ny\nz")
+	assert.StrContains(t, doc.String(), "list = \"\"\"x\ny\nz\"\"\"\n")
+}
@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 
-	"github.com/gookit/goutil/structs"
 	"github.com/gookit/goutil/timex"
 )
 
 type EncodeOptions struct {
 	// DefSection name
 	DefSection string
+	// TagName of the struct field for encode struct. default is TagName("ini")
+	TagName string
 	// AddExportDate add export date to head of file. default: true
 	AddExportDate bool
 	// Comments comments map, key is `section +"_"+ key`, value is comment.
@@ -21,6 +24,40 @@ type EncodeOptions struct {
 	//
 	// TIP: if you want to set raw value to INI file, you can use this option. see `rawBak` in ini.Ini
 	RawValueMap map[string]string
+	// SectionOrder order of the sections. if not empty, will encode sections by the order,
+	// the sections not in it will be sorted and appended.
+	SectionOrder []string
+	// KeyOrder order of keys in each section, key is section name.
+	// the keys not in it will be sorted and appended.
+	KeyOrder map[string][]string
+}
+
+// sortByOrder sort the names by order, the names not in order will be sorted and appended.
+func sortByOrder(names, order []string) []string {
+	sort.Strings(names)
+	if len(order) == 0 {
+		return names
+	}
+
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for _, name := range order {
+		if exists[name] {
+			sorted = append(sorted, name)
+			delete(exists, name)
+		}
+	}
+
+	for _, name := range names {
+		if exists[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	return sorted
 }
 
 func newEncodeOptions(defSection []string) *EncodeOptions {
@@ -43,13 +80,12 @@ func EncodeWith(v any, opts *EncodeOptions) ([]byte, error) {
 	case map[string]map[string]string: // from lite mode
 		return encodeLite(vd, opts)
 	default:
-		if vd != nil {
-			// as struct data, use structs.ToMap convert
-			anyMap, err := structs.StructToMap(vd)
-			if err != nil {
-				return nil, err
+		// as struct data
+		if rv := indirectValue(reflect.ValueOf(v)); rv.Kind() == reflect.Struct {
+			if opts.TagName == "" {
+				opts.TagName = TagName
 			}
-			return encodeFull(anyMap, opts)
+			return encodeStruct(rv, opts)
 		}
 		return nil, errors.New("ini: invalid data to encode as INI")
 	}
@@ -97,32 +133,43 @@ func encodeFull(data map[string]any, opts *EncodeOptions) (out []byte, err error
 		buf.WriteString("; exported at " + timex.Now().Datetime() + "\n\n")
 	}
 
-	sort.Strings(sortedGroups)
-	maxLn := len(sortedGroups) - 1
+	sortedGroups = sortByOrder(sortedGroups, opts.SectionOrder)
 	secBuf := &bytes.Buffer{}
 
-	for idx, section := range sortedGroups {
+	for _, section := range sortedGroups {
 		item := data[section]
 		switch tpData := item.(type) {
 		case []int:
 		case []string: // array of the default section
+			if s, ok := opts.Comments[defSecName+"_"+section]; ok {
+				buf.WriteString(s + "\n")
+			}
 			for _, v := range tpData {
-				buf.WriteString(fmt.Sprintf("%s[] = %v\n", section, v))
+				buf.WriteString(section + "[] = " + formatValue(v) + "\n")
 			}
 		// case map[string]string: // is section
 		case map[string]any: // is section
-			if section != defSecName {
-				secBuf.WriteString("[" + section + "]\n")
-				writeAnyMap(secBuf, tpData)
-			} else {
-				writeAnyMap(buf, tpData)
+			if section == defSecName {
+				writeAnyMap(buf, tpData, section, opts)
+				continue
 			}
 
-			if idx < maxLn {
+			if secBuf.Len() > 0 {
 				secBuf.WriteByte('\n')
 			}
+
+			// comments for section
+			if s, ok := opts.Comments[section]; ok {
+				secBuf.WriteString(s + "\n")
+			}
+
+			secBuf.WriteString("[" + section + "]\n")
+			writeAnyMap(secBuf, tpData, section, opts)
 		default: // k-v of the default section
-			buf.WriteString(fmt.Sprintf("%s = %v\n", section, tpData))
+			if s, ok := opts.Comments[defSecName+"_"+section]; ok {
+				buf.WriteString(s + "\n")
+			}
+			buf.WriteString(section + " = " + formatValue(fmt.Sprint(tpData)) + "\n")
 		}
 	}
 
@@ -133,19 +180,37 @@ func encodeFull(data map[string]any, opts *EncodeOptions) (out []byte, err error
 	return
 }
 
-func writeAnyMap(buf *bytes.Buffer, data map[string]any) {
-	for key, item := range data {
+func writeAnyMap(buf *bytes.Buffer, data map[string]any, section string, opts *EncodeOptions) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	for _, key := range sortByOrder(keys, opts.KeyOrder[section]) {
+		item := data[key]
+		keyPath := section + "_" + key
+		// add comments
+		if s, ok := opts.Comments[keyPath]; ok {
+			buf.WriteString(s + "\n")
+		}
+
+		if val, ok := opts.RawValueMap[keyPath]; ok {
+			if _, isArr := item.([]string); !isArr {
+				item = val
+			}
+		}
+
 		switch tpData := item.(type) {
 		case []int:
 		case []string: // array of the default section
 			for _, v := range tpData {
 				buf.WriteString(key + "[] = ")
-				buf.WriteString(fmt.Sprint(v))
+				buf.WriteString(formatValue(v))
 				buf.WriteByte('\n')
 			}
 		default: // k-v of the section
 			buf.WriteString(key + " = ")
-			buf.WriteString(fmt.Sprint(tpData))
+			buf.WriteString(formatValue(fmt.Sprint(tpData)))
 			buf.WriteByte('\n')
 		}
 	}
@@ -178,7 +243,7 @@ func encodeLite(data map[string]map[string]string, opts *EncodeOptions) (out []b
 		buf.WriteByte('\n')
 	}
 
-	sort.Strings(sortedGroups)
+	sortedGroups = sortByOrder(sortedGroups, opts.SectionOrder)
 	maxLn := len(sortedGroups) - 1
 	for idx, section := range sortedGroups {
 		// comments for section
@@ -204,8 +269,7 @@ func writeStrMap(buf *bytes.Buffer, strMap map[string]string, section string, op
 		sortedKeys = append(sortedKeys, key)
 	}
 
-	sort.Strings(sortedKeys)
-	for _, key := range sortedKeys {
+	for _, key := range sortByOrder(sortedKeys, opts.KeyOrder[section]) {
 		value := strMap[key]
 		keyPath := section + "_" + key
 		// add comments
@@ -216,6 +280,76 @@ func writeStrMap(buf *bytes.Buffer, strMap map[string]string, section string, op
 		if val1, ok := opts.RawValueMap[keyPath]; ok {
 			value = val1
 		}
-		buf.WriteString(key + " = " + value + "\n")
+		buf.WriteString(key + " = " + formatValue(value) + "\n")
 	}
 }
+
+// formatValue for encode, make sure encode then parse is lossless.
+//
+//   - multi line value will be wrapped by triple quotes.
+//   - the value contains special chars will be quoted and escaped. see NeedQuote
+func formatValue(val string) string {
+	if strings.ContainsRune(val, '\n') && !strings.ContainsRune(val, '\r') {
+		if !strings.Contains(val, MultiLineMark) {
+			return MultiLineMark + val + MultiLineMark
+		}
+		if !strings.Contains(val, MultiLineMarkS) {
+			return MultiLineMarkS + val + MultiLineMarkS
+		}
+	}
+
+	if NeedQuote(val) {
+		return QuoteValue(val)
+	}
+	return val
+}
+
+// NeedQuote check the value need to be quoted on encode. the value:
+//
+//   - contains ';', '#', '=' or newlines
+//   - has leading or trailing spaces
+//   - start with quote char, or end with '\'
+func NeedQuote(val string) bool {
+	ln := len(val)
+	if ln == 0 {
+		return false
+	}
+
+	switch {
+	case val[0] == ' ' || val[0] == '\t' || val[ln-1] == ' ' || val[ln-1] == '\t':
+		return true
+	case val[0] == '"' || val[0] == '\'' || val[ln-1] == '\\':
+		return true
+	}
+	return strings.ContainsAny(val, ";#=\n\r")
+}
+
+// QuoteValue wrap the value by double quotes, and escape the special chars.
+func QuoteValue(val string) string {
+	var sb strings.Builder
+	sb.Grow(len(val) + 2)
+	sb.WriteByte('"')
+
+	for _, r := range val {
+		switch r {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < ' ' || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// struct field tag info. eg: `ini:"name,omitempty" comment:"some comments"`
+type fieldTag struct {
+	name      string
+	omitEmpty bool
+	comment   string
+}
+
+// parse field tag by tag name, will fall back to the json tag.
+func parseFieldTag(sf reflect.StructField, tagName string) (ft fieldTag, skip bool) {
+	tag, ok := sf.Tag.Lookup(tagName)
+	if !ok && tagName != "json" {
+		tag, ok = sf.Tag.Lookup("json")
+	}
+
+	if ok {
+		if tag == "-" {
+			return ft, true
+		}
+
+		nodes := strings.Split(tag, ",")
+		ft.name = strings.TrimSpace(nodes[0])
+		for _, opt := range nodes[1:] {
+			if strings.TrimSpace(opt) == "omitempty" {
+				ft.omitEmpty = true
+			}
+		}
+	}
+
+	if ft.name == "" {
+		ft.name = sf.Name
+	}
+
+	ft.comment = sf.Tag.Get("comment")
+	return ft, false
+}
+
+// structEncoder encode struct to the full mode data, collect comments and orders.
+type structEncoder struct {
+	opts *EncodeOptions
+	data map[string]any
+	// collected comments and orders
+	comments map[string]string
+	secOrder []string
+	keyOrder map[string][]string
+}
+
+// encodeStruct encode struct to INI. the opts will not be modified.
+//
+//   - honor the tag by EncodeOptions.TagName, support `omitempty` and "-"
+//   - nested struct field will be a section, the deeper nested struct will be a "parent.child" section
+//   - slice field will be "key[] = val" lines, map field in section will be "key[sub] = val" lines
+//   - the `comment:"..."` tag will be emitted above the key or section
+func encodeStruct(rv reflect.Value, opts *EncodeOptions) ([]byte, error) {
+	se := &structEncoder{
+		opts:     opts,
+		data:     make(map[string]any),
+		comments: make(map[string]string, len(opts.Comments)),
+		keyOrder: make(map[string][]string),
+	}
+
+	for k, v := range opts.Comments {
+		se.comments[k] = v
+	}
+
+	if err := se.encodeTop(rv); err != nil {
+		return nil, err
+	}
+
+	// copy options, fill the collected comments and orders
+	newOpts := *opts
+	newOpts.Comments = se.comments
+	if len(newOpts.SectionOrder) == 0 {
+		newOpts.SectionOrder = se.secOrder
+	}
+	if len(newOpts.KeyOrder) == 0 {
+		newOpts.KeyOrder = se.keyOrder
+	}
+	return encodeFull(se.data, &newOpts)
+}
+
+// encode top struct, the simple fields will be in the default section.
+func (se *structEncoder) encodeTop(rv reflect.Value) error {
+	defSec := se.opts.DefSection
+
+	return se.eachField(rv, func(ft fieldTag, fv reflect.Value) error {
+		if isStructValue(fv) {
+			se.addComment(ft.name, ft.comment)
+			se.secOrder = append(se.secOrder, ft.name)
+			return se.encodeSection(ft.name, fv)
+		}
+
+		if fv.Kind() == reflect.Map {
+			se.addComment(ft.name, ft.comment)
+			se.secOrder = append(se.secOrder, ft.name)
+			se.data[ft.name] = mapToSection(fv)
+			return nil
+		}
+
+		// default section values
+		se.addComment(defSec+"_"+ft.name, ft.comment)
+		val, err := fieldValue(fv)
+		if err != nil {
+			return fmt.Errorf("ini: encode field %q: %w", ft.name, err)
+		}
+
+		if defSec != "" {
+			sec, ok := se.data[defSec].(map[string]any)
+			if !ok {
+				sec = make(map[string]any)
+				se.data[defSec] = sec
+			}
+
+			sec[ft.name] = val
+			se.keyOrder[defSec] = append(se.keyOrder[defSec], ft.name)
+		} else {
+			se.data[ft.name] = val
+			se.secOrder = append(se.secOrder, ft.name)
+		}
+		return nil
+	})
+}
+
+// encode struct as a section
+func (se *structEncoder) encodeSection(name string, rv reflect.Value) error {
+	sec := make(map[string]any)
+	se.data[name] = sec
+
+	return se.eachField(rv, func(ft fieldTag, fv reflect.Value) error {
+		// nested struct as sub section. eg: [parent.child]
+		if isStructValue(fv) {
+			subName := name + "." + ft.name
+			se.addComment(subName, ft.comment)
+			se.secOrder = append(se.secOrder, subName)
+			return se.encodeSection(subName, fv)
+		}
+
+		se.addComment(name+"_"+ft.name, ft.comment)
+
+		// map in section. eg: key[sub] = val
+		if fv.Kind() == reflect.Map {
+			subMp := mapToSection(fv)
+			for _, sub := range sortByOrder(mapKeys(subMp), nil) {
+				key := ft.name + "[" + sub + "]"
+				sec[key] = subMp[sub]
+				se.keyOrder[name] = append(se.keyOrder[name], key)
+			}
+			return nil
+		}
+
+		val, err := fieldValue(fv)
+		if err != nil {
+			return fmt.Errorf("ini: encode field %q of section %q: %w", ft.name, name, err)
+		}
+
+		sec[ft.name] = val
+		se.keyOrder[name] = append(se.keyOrder[name], ft.name)
+		return nil
+	})
+}
+
+// each exported and not skipped fields of the struct, the embedded struct fields will be inlined.
+func (se *structEncoder) eachField(rv reflect.Value, fn func(ft fieldTag, fv reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+
+		ft, skip := parseFieldTag(sf, se.opts.TagName)
+		if skip {
+			continue
+		}
+
+		fv := indirectValue(rv.Field(i))
+		if !fv.IsValid() || (ft.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		// inline the anonymous struct fields, if it has no tag name
+		if sf.Anonymous && fv.Kind() == reflect.Struct && ft.name == sf.Name {
+			if err := se.eachField(fv, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(ft, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (se *structEncoder) addComment(key, comment string) {
+	if comment == "" {
+		return
+	}
+
+	if !IsCommentChar(comment[0]) {
+		comment = "; " + comment
+	}
+	se.comments[key] = comment
+}
+
+// fieldValue convert field value to string or []string
+func fieldValue(fv reflect.Value) (any, error) {
+	if str, ok, err := marshalText(fv); ok {
+		return str, err
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice, reflect.Array:
+		// []byte, [N]byte as string
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			if fv.Kind() == reflect.Slice {
+				return string(fv.Bytes()), nil
+			}
+
+			// the array may be unaddressable, eg: the struct passed by value
+			bs := make([]byte, fv.Len())
+			for i := range bs {
+				bs[i] = byte(fv.Index(i).Uint())
+			}
+			return string(bs), nil
+		}
+
+		ss := make([]string, fv.Len())
+		for i := range ss {
+			ev := indirectValue(fv.Index(i))
+			if !ev.IsValid() {
+				continue
+			}
+
+			if str, ok, err := marshalText(ev); ok {
+				if err != nil {
+					return nil, err
+				}
+				ss[i] = str
+			} else {
+				ss[i] = fmt.Sprint(ev.Interface())
+			}
+		}
+		return ss, nil
+	case reflect.Func, reflect.Chan, reflect.Interface:
+		return nil, errors.New("unsupported field type " + fv.Type().String())
+	default:
+		return fmt.Sprint(fv.Interface()), nil
+	}
+}
+
+// mapToSection convert map value to section data
+func mapToSection(mv reflect.Value) map[string]any {
+	sec := make(map[string]any, mv.Len())
+	iter := mv.MapRange()
+	for iter.Next() {
+		sec[fmt.Sprint(iter.Key().Interface())] = fmt.Sprint(iter.Value().Interface())
+	}
+	return sec
+}
+
+func mapKeys(mp map[string]any) []string {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// marshalText by encoding.TextMarshaler, ok is false on not implemented.
+func marshalText(rv reflect.Value) (str string, ok bool, err error) {
+	tm, ok := rv.Interface().(encoding.TextMarshaler)
+	if !ok && rv.CanAddr() {
+		tm, ok = rv.Addr().Interface().(encoding.TextMarshaler)
+	}
+	if !ok {
+		return
+	}
+
+	bs, err := tm.MarshalText()
+	return string(bs), true, err
+}
+
+// isStructValue check is a struct value, but not a TextMarshaler or Stringer. eg: time.Time
+func isStructValue(rv reflect.Value) bool {
+	if rv.Kind() != reflect.Struct {
+		return false
+	}
+
+	val := rv.Interface()
+	if rv.CanAddr() {
+		val = rv.Addr().Interface()
+	}
+
+	switch val.(type) {
+	case encoding.TextMarshaler, fmt.Stringer:
+		return false
+	}
+	return true
+}
+
+// indirectValue get the real value of pointer and interface, returns invalid value on nil.
+func indirectValue(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
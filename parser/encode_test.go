@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gookit/goutil/testutil/assert"
 	"github.com/gookit/ini/v2/internal"
@@ -173,3 +174,211 @@ func TestEncodeWith(t *testing.T) {
 func TestMapStruct_err(t *testing.T) {
 	assert.Err(t, internal.MapStruct("json", "invalid", nil))
 }
+
+func TestEncodeWith_order(t *testing.T) {
+	out, err := EncodeWith(liteData, &EncodeOptions{
+		DefSection:   "z_def",
+		SectionOrder: []string{"z_def", "sec"},
+		KeyOrder: map[string][]string{
+			"z_def": {"name", "age"},
+			"sec":   {"key1"},
+		},
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "name = inhere\nage = 100\n\n[sec]\nkey1 = 34\nkey = val\n", string(out))
+
+	out, err = EncodeWith(map[string]any{
+		"b":   "2",
+		"a":   "1",
+		"sec": map[string]any{"y": 1, "x": 2},
+		"abc": map[string]any{"k": "v"},
+	}, &EncodeOptions{
+		SectionOrder: []string{"b", "sec"},
+		KeyOrder:     map[string][]string{"sec": {"y", "x"}},
+	})
+	assert.NoErr(t, err)
+	assert.Eq(t, "b = 2\na = 1\n\n[sec]\ny = 1\nx = 2\n\n[abc]\nk = v\n", string(out))
+}
+
+func TestEncode_multiLineValue(t *testing.T) {
+	data := map[string]map[string]string{
+		DefSection: {"cert": "-----BEGIN-----\n  MIIB\n-----END-----\n", "name": "inhere"},
+		"sec":      {"sql": "select \"\"\"\nfrom users", "empty": "\n"},
+	}
+
+	out, err := EncodeWith(data, &EncodeOptions{DefSection: DefSection})
+	assert.NoErr(t, err)
+	assert.StrContains(t, string(out), "cert = \"\"\"-----BEGIN-----\n  MIIB\n-----END-----\n\"\"\"\n")
+	assert.StrContains(t, string(out), "sql = '''select \"\"\"\nfrom users'''\n")
+
+	// re-parse
+	p := New()
+	assert.NoErr(t, p.ParseBytes(out))
+	assert.Eq(t, data, p.LiteData())
+
+	// full mode
+	out, err = EncodeWith(map[string]any{
+		"desc": "a\nb",
+		"sec":  map[string]any{"tags": []string{"x\ny", "z"}},
+	}, &EncodeOptions{})
+	assert.NoErr(t, err)
+	p = New(WithParseMode(ModeFull))
+	assert.NoErr(t, p.ParseBytes(out))
+	assert.Eq(t, "a\nb", p.FullData()[DefSection].(map[string]any)["desc"])
+	assert.Eq(t, []string{"x\ny", "z"}, p.FullData()["sec"].(map[string]any)["tags"])
+}
+
+func TestEncode_quoteValue(t *testing.T) {
+	is := assert.New(t)
+	is.False(NeedQuote(""))
+	is.False(NeedQuote("abc def"))
+	is.True(NeedQuote(" abc"))
+	is.True(NeedQuote("abc\t"))
+	is.True(NeedQuote("a;b"))
+	is.True(NeedQuote("a#b"))
+	is.True(NeedQuote("a=b"))
+	is.True(NeedQuote(`"abc`))
+	is.True(NeedQuote(`C:\dir\`))
+	is.Eq(`"a\"b\\c\n\t\u0001"`, QuoteValue("a\"b\\c\n\t\x01"))
+
+	data := map[string]map[string]string{
+		DefSection: {
+			"semi":  "a;b",
+			"hash":  "a # b",
+			"space": "  val  ",
+			"eq":    "k=v",
+			"quote": `"quoted" value`,
+			"sq":    `'single'`,
+			"bs":    `C:\dir\`,
+			"cr":    "line1\r\nline2",
+			"both":  "a\"\"\"\nb'''",
+			"uni":   "中文\x01",
+			"plain": `C:\dir\file`,
+		},
+	}
+
+	out, err := EncodeWith(data, &EncodeOptions{DefSection: DefSection})
+	is.NoErr(err)
+	is.StrContains(string(out), `semi = "a;b"`)
+	is.StrContains(string(out), `plain = C:\dir\file`)
+
+	p := New()
+	is.NoErr(p.ParseBytes(out))
+	is.Eq(data, p.LiteData())
+
+	p = New(InlineComment)
+	is.NoErr(p.ParseBytes(out))
+	is.Eq(data, p.LiteData())
+}
+
+func TestEncodeWith_structTags(t *testing.T) {
+	is := assert.New(t)
+
+	type Base struct {
+		Env string `ini:"env"`
+	}
+	type Replica struct {
+		Host string `ini:"host"`
+	}
+	type Database struct {
+		Host    string            `ini:"host" comment:"database host"`
+		Port    int               `ini:"port,omitempty"`
+		Hosts   []string          `ini:"hosts"`
+		Users   map[string]int    `ini:"users"`
+		Replica *Replica          `ini:"replica" comment:"# replica server"`
+		Nil     *Replica          `ini:"nil"`
+		Labels  map[string]string `ini:"-"`
+	}
+	type Config struct {
+		Base
+		Name    string    `ini:"name" comment:"app name"`
+		Debug   bool      `ini:"debug,omitempty"`
+		Tags    []int     `ini:"tags"`
+		Secret  string    `ini:"-"`
+		Created time.Time `ini:"created"`
+		DB      Database  `ini:"db" comment:"db config"`
+		Extra   map[string]string
+		private string
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := &Config{
+		Base:    Base{Env: "prod"},
+		Name:    "app",
+		Tags:    []int{1, 2},
+		Secret:  "secret",
+		Created: created,
+		DB: Database{
+			Host:    "127.0.0.1",
+			Hosts:   []string{"a", "b"},
+			Users:   map[string]int{"tom": 1, "amy": 2},
+			Replica: &Replica{Host: "10.0.0.2"},
+			Labels:  map[string]string{"k": "v"},
+		},
+		Extra:   map[string]string{"key": "val"},
+		private: "private",
+	}
+
+	out, err := EncodeWith(cfg, &EncodeOptions{})
+	is.NoErr(err)
+	str := string(out)
+	fmt.Println(str)
+
+	is.Eq(`env = prod
+; app name
+name = app
+tags[] = 1
+tags[] = 2
+created = 2024-01-02T03:04:05Z
+
+; db config
+[db]
+; database host
+host = 127.0.0.1
+hosts[] = a
+hosts[] = b
+users[amy] = 2
+users[tom] = 1
+
+# replica server
+[db.replica]
+host = 10.0.0.2
+
+[Extra]
+key = val
+`, str)
+	is.NotContains(str, "secret")
+	is.NotContains(str, "debug")
+	is.NotContains(str, "private")
+
+	// re-parse and decode
+	p := NewFulled(NoDefSection)
+	is.NoErr(p.ParseBytes(out))
+	is.Eq([]string{"1", "2"}, p.FullData()["tags"])
+	is.Eq(map[string]any{"host": "10.0.0.2"}, p.FullData()["db.replica"])
+
+	// with default section and custom tag name
+	type Sample struct {
+		Name string `conf:"app_name" ini:"name"`
+		Sec  struct {
+			Key string `conf:"the_key"`
+		} `conf:"sec"`
+	}
+	out, err = EncodeWith(Sample{Name: "inhere"}, &EncodeOptions{DefSection: DefSection, TagName: "conf"})
+	is.NoErr(err)
+	is.Eq("app_name = inhere\n\n[sec]\nthe_key = \n", string(out))
+
+	// by value struct contains arrays
+	type Arrays struct {
+		ID    [4]byte `ini:"id"`
+		Ports [2]int  `ini:"ports"`
+		Raw   []byte  `ini:"raw"`
+	}
+	out, err = EncodeWith(Arrays{ID: [4]byte{'a', 'b', 'c', 'd'}, Ports: [2]int{80, 443}, Raw: []byte("raw")}, &EncodeOptions{})
+	is.NoErr(err)
+	is.Eq("id = abcd\nports[] = 80\nports[] = 443\nraw = raw\n\n", string(out))
+
+	// unsupported field
+	_, err = EncodeWith(struct{ Fn func() }{Fn: func() {}}, nil)
+	is.ErrSubMsg(err, `ini: encode field "Fn": unsupported field type func()`)
+}
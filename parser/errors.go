@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gookit/goutil/strutil/textscan"
+)
+
+// SyntaxError error on parse INI contents
+type SyntaxError struct {
+	// File name, from Options.FileName. allow empty
+	File string
+	// Line number, start 1
+	Line int
+	// Column number, start 1
+	Column int
+	// Text contents of the error line
+	Text string
+	// Reason of the error
+	Reason string
+}
+
+// Error string. eg: `ini: app.ini: line 3, column 1: invalid syntax, no matcher available: "some"`
+func (e *SyntaxError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("ini: %s: line %d, column %d: %s: %q", e.File, e.Line, e.Column, e.Reason, e.Text)
+	}
+	return fmt.Sprintf("ini: line %d, column %d: %s: %q", e.Line, e.Column, e.Reason, e.Text)
+}
+
+// newSyntaxError create
+func (p *Parser) newSyntaxError(line int, text, reason string) *SyntaxError {
+	return &SyntaxError{
+		File:   p.FileName,
+		Line:   line,
+		Column: textColumn(text),
+		Text:   text,
+		Reason: reason,
+	}
+}
+
+// convert textscan.ErrScan to SyntaxError
+func (p *Parser) wrapScanErr(err error) error {
+	var se textscan.ErrScan
+	if errors.As(err, &se) {
+		return p.newSyntaxError(se.Line, se.Text, se.Msg)
+	}
+	return err
+}
+
+// column of the first non-space char, start 1
+func textColumn(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " \t")) + 1
+}
+
+// posMatcher wrap a matcher, record the start line and text of the matched token.
+type posMatcher struct {
+	textscan.Matcher
+	ts   *textscan.TextScanner
+	line int
+	text string
+}
+
+// Match line text by the wrapped matcher
+func (m *posMatcher) Match(text string, prev textscan.Token) (textscan.Token, error) {
+	tok, err := m.Matcher.Match(text, prev)
+	if tok != nil {
+		m.line, m.text = m.ts.Line(), text
+	}
+	return tok, err
+}
+
+// checkKey on strict mode. allow: "key", "key[]", "key[sub]"
+func checkKey(key string) error {
+	if strings.ContainsAny(key, " \t") {
+		return fmt.Errorf("invalid key %q, cannot contain spaces", key)
+	}
+
+	if pos := strings.IndexByte(key, '['); pos > -1 {
+		if pos == 0 || !strings.HasSuffix(key, "]") || strings.Count(key, "[") > 1 || strings.Count(key, "]") > 1 {
+			return fmt.Errorf("invalid key %q", key)
+		}
+	} else if strings.IndexByte(key, ']') > -1 {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
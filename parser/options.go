@@ -15,6 +15,38 @@ const DefSection = "__default"
 
 type parseMode uint8
 
+// DupPolicy policy of the duplicate keys in a section
+type DupPolicy uint8
+
+// duplicate key policies
+//
+//	DupLastWins  - the last value will override the previous value. it is default
+//	DupFirstWins - keep the first value, ignore the later values
+//	DupError     - return SyntaxError on found duplicate key
+//	DupCollect   - collect the values as array. NOTE: only for full parse mode, lite mode will return SyntaxError
+const (
+	DupLastWins DupPolicy = iota
+	DupFirstWins
+	DupError
+	DupCollect
+)
+
+// String policy name
+func (d DupPolicy) String() string {
+	switch d {
+	case DupLastWins:
+		return "last-wins"
+	case DupFirstWins:
+		return "first-wins"
+	case DupError:
+		return "error"
+	case DupCollect:
+		return "collect-as-array"
+	default:
+		return "unknown"
+	}
+}
+
 // Unit8 mode value to uint8
 func (m parseMode) Unit8() uint8 {
 	return uint8(m)
@@ -31,6 +63,10 @@ type OptFunc func(opt *Options)
 // Notice: in lite mode, isSlice always is false.
 type UserCollector func(section, key, val string, isSlice bool)
 
+// IncludeFunc handle the include directive on parsing.
+// eg: load the include file data. return error will stop parsing.
+type IncludeFunc func(inc Include) error
+
 // Options for parser
 type Options struct {
 	// TagName of mapping data to struct
@@ -47,10 +83,30 @@ type Options struct {
 	NoDefSection bool
 	// InlineComment support parse inline comments. default is false
 	InlineComment bool
+	// ParseInherit parse section inheritance declaration. eg: [child : parent]. default is false
+	ParseInherit bool
+	// IndentContinue allow indented continuation lines for multi line value, like Python configparser. default is false
+	//
+	// The lines indented deeper than the key line will be joined with newline.
+	IndentContinue bool
+	// DupPolicy for the duplicate keys in a section. default is DupLastWins
+	DupPolicy DupPolicy
+	// Strict mode, unrecognized lines, duplicate keys and empty section names will return SyntaxError. default is false
+	Strict bool
+	// FileName of the parsing contents, use for the SyntaxError. allow empty
+	FileName string
+	// ParseInclude parse include directive. eg: "include = file.ini", "!include conf.d/*.ini". default is false
+	ParseInclude bool
+	// KeepDocument retain the document model(sections, keys, comments, blank lines
+	// and ordering) on parsing, use for round-trip edit and write back. default is false
+	KeepDocument bool
 	// Collector allow you custom the value collector.
 	//
 	// Notice: in lite mode, isSlice always is false.
 	Collector UserCollector
+	// Includer handle the include directive on parsing, only on ParseInclude=true.
+	// If is nil, the directives only be recorded, can get them by Parser.Includes()
+	Includer IncludeFunc
 }
 
 // NewOptions instance
@@ -70,6 +126,35 @@ func NewOptions(fns ...OptFunc) *Options {
 // InlineComment for parse
 func InlineComment(opt *Options) { opt.InlineComment = true }
 
+// ParseInherit for parse section inheritance. eg: [child : parent]
+func ParseInherit(opt *Options) { opt.ParseInherit = true }
+
+// IndentContinue for parse multi line value
+func IndentContinue(opt *Options) { opt.IndentContinue = true }
+
+// Strict mode for parse
+func Strict(opt *Options) { opt.Strict = true }
+
+// WithDupPolicy for parse duplicate keys
+func WithDupPolicy(policy DupPolicy) OptFunc {
+	return func(opt *Options) {
+		opt.DupPolicy = policy
+	}
+}
+
+// WithFileName for parse, use for the SyntaxError
+func WithFileName(name string) OptFunc {
+	return func(opt *Options) {
+		opt.FileName = name
+	}
+}
+
+// ParseInclude for parse include directive
+func ParseInclude(opt *Options) { opt.ParseInclude = true }
+
+// KeepDocument for parse
+func KeepDocument(opt *Options) { opt.KeepDocument = true }
+
 // WithReplaceNl for parse
 func WithReplaceNl(opt *Options) { opt.ReplaceNl = true }
 
@@ -33,10 +33,12 @@ package parser
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/gookit/goutil/strutil/textscan"
@@ -50,6 +52,9 @@ var commentChars = []byte{'#', ';'}
 // TokSection for mark a section
 const TokSection = textscan.TokComments + 1 + iota
 
+// TokInclude for mark an include directive
+const TokInclude = TokSection + 1
+
 // IsCommentChar check is comment char
 func IsCommentChar(ch byte) bool {
 	for _, v := range commentChars {
@@ -60,8 +65,20 @@ func IsCommentChar(ch byte) bool {
 	return false
 }
 
+// SectionToken for section line. eg: [section], [child : parent]
+type SectionToken struct {
+	*textscan.StringToken
+	// Parent section name, only on SectionMatcher.Inherit=true
+	Parent string
+}
+
 // SectionMatcher match section line: [section]
-type SectionMatcher struct{}
+type SectionMatcher struct {
+	// Inherit parse section inheritance. eg: [child : parent]
+	Inherit bool
+	// Strict mode, don't allow empty section name
+	Strict bool
+}
 
 // Match section line: [section]
 func (m *SectionMatcher) Match(text string, _ textscan.Token) (textscan.Token, error) {
@@ -69,13 +86,98 @@ func (m *SectionMatcher) Match(text string, _ textscan.Token) (textscan.Token, e
 
 	if matched := sectionRegex.FindStringSubmatch(line); matched != nil {
 		section := strings.TrimSpace(matched[1])
+
+		var parent string
+		if m.Inherit {
+			section, parent = SplitInherit(section)
+		}
+
+		if m.Strict && section == "" {
+			return nil, errors.New("section name cannot be empty")
+		}
+
 		tok := textscan.NewStringToken(TokSection, section)
-		return tok, nil
+		return &SectionToken{StringToken: tok, Parent: parent}, nil
 	}
 
 	return nil, nil
 }
 
+// SplitInherit split section inheritance declaration. eg: "child : parent" => "child", "parent"
+func SplitInherit(section string) (name, parent string) {
+	if pos := strings.IndexByte(section, ':'); pos > -1 {
+		return strings.TrimSpace(section[:pos]), strings.TrimSpace(section[pos+1:])
+	}
+	return section, ""
+}
+
+// IncludeMatcher match include directive line: "include = file.ini", "!include conf.d/*.ini"
+type IncludeMatcher struct{}
+
+// Match include directive line
+func (m *IncludeMatcher) Match(text string, _ textscan.Token) (textscan.Token, error) {
+	line := strings.TrimSpace(text)
+
+	var path string
+	if strings.HasPrefix(line, "!include") {
+		path = line[8:]
+		// must be separated by space. eg: "!include file.ini"
+		if path == "" || (path[0] != ' ' && path[0] != '\t') {
+			return nil, nil
+		}
+	} else if strings.HasPrefix(line, "include") {
+		path = strings.TrimLeft(line[7:], " \t")
+		if path == "" || path[0] != '=' {
+			return nil, nil
+		}
+		path = path[1:]
+	} else {
+		return nil, nil
+	}
+
+	path = strings.TrimSpace(path)
+	if ln := len(path); ln > 1 && (path[0] == '"' || path[0] == '\'') && path[ln-1] == path[0] {
+		path = path[1 : ln-1]
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("include file path cannot be empty")
+	}
+	return textscan.NewStringToken(TokInclude, path), nil
+}
+
+// Include directive info
+type Include struct {
+	// Section name of the directive in
+	Section string
+	// Path of the include file, it is raw value. eg: "conf.d/*.ini"
+	Path string
+	// Line number of the directive
+	Line int
+}
+
+// Position of the key or section in the source contents
+type Position struct {
+	// File name, from Options.FileName. allow empty
+	File string
+	// Line number, start 1
+	Line int
+}
+
+// String position. eg: "app.ini:3", "line 3"
+func (p Position) String() string {
+	if p.File != "" {
+		return p.File + ":" + strconv.Itoa(p.Line)
+	}
+	return "line " + strconv.Itoa(p.Line)
+}
+
+// PosKey key of the source positions. Key is empty on it is a section position.
+type PosKey struct {
+	Section string
+	Key     string
+}
+
 // Parser definition for parse INI content.
 type Parser struct {
 	*Options
@@ -83,6 +185,14 @@ type Parser struct {
 
 	// comments map, key is name
 	comments map[string]string
+	// positions map, the key of section position is PosKey{Section: section}.
+	positions map[PosKey]Position
+	// document model, only on Options.KeepDocument=true
+	doc *Document
+	// section inheritance map, key is child name, value is parent name.
+	parents map[string]string
+	// include directives, only on Options.ParseInclude=true
+	includes []Include
 
 	// for full parse(allow array, map section)
 	fullData map[string]any
@@ -162,10 +272,22 @@ func (p *Parser) Unmarshal(v []byte, ptr any) error {
 
 // ParseString parse from string data
 func (p *Parser) ParseString(str string) error {
-	if str = strings.TrimSpace(str); str == "" {
+	if p.KeepDocument {
+		p.doc = NewDocument(p.DefSection)
+		p.doc.Inherit = p.ParseInherit
+		p.doc.IgnoreCase = p.IgnoreCase
+		p.doc.IndentContinue = p.IndentContinue
+		p.doc.DupPolicy = p.DupPolicy
+		p.doc.parse(str)
+	}
+
+	// NOTE: don't trim the leading lines, keep the line number is correct.
+	if strings.TrimSpace(str) == "" {
 		return nil
 	}
-	return p.ParseReader(strings.NewReader(str))
+
+	_, err := p.ParseFrom(bufio.NewScanner(strings.NewReader(str)))
+	return err
 }
 
 // ParseBytes parse from bytes data
@@ -173,11 +295,23 @@ func (p *Parser) ParseBytes(bts []byte) (err error) {
 	if len(bts) == 0 {
 		return nil
 	}
+
+	if p.KeepDocument {
+		return p.ParseString(string(bts))
+	}
 	return p.ParseReader(bytes.NewBuffer(bts))
 }
 
 // ParseReader parse from io reader
 func (p *Parser) ParseReader(r io.Reader) (err error) {
+	if p.KeepDocument {
+		bts, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return p.ParseString(string(bts))
+	}
+
 	_, err = p.ParseFrom(bufio.NewScanner(r))
 	return
 }
@@ -188,6 +322,9 @@ func (p *Parser) init() {
 	// 	p.DefSection = strings.ToLower(p.DefSection)
 	// }
 	p.comments = make(map[string]string)
+	p.positions = make(map[PosKey]Position)
+	p.parents = make(map[string]string)
+	p.includes = nil
 
 	if p.ParseMode == ModeFull {
 		p.fullData = make(map[string]any)
@@ -212,18 +349,40 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 	// create scanner
 	ts := textscan.NewScanner(in)
 	ts.AddKind(TokSection, "Section")
+	if p.IndentContinue {
+		ts.AddMatchers(&nextResetter{ts: ts})
+	}
+
 	ts.AddMatchers(
 		&textscan.CommentsMatcher{
 			InlineChars: commentChars,
 		},
-		&SectionMatcher{},
-		&textscan.KeyValueMatcher{
-			MergeComments: true,
-			InlineComment: p.InlineComment,
-		},
+		&SectionMatcher{Inherit: p.ParseInherit, Strict: p.Strict},
 	)
 
+	if p.ParseInclude {
+		ts.AddKind(TokInclude, "Include")
+		ts.AddMatchers(&IncludeMatcher{})
+	}
+
+	kvm := &ValueMatcher{
+		InlineComment:  p.InlineComment,
+		IndentContinue: p.IndentContinue,
+	}
+	if p.Strict {
+		kvm.KeyCheckFn = checkKey
+	}
+
+	// record position of the key-value line
+	pm := &posMatcher{Matcher: kvm, ts: ts}
+	ts.AddMatchers(pm)
+
 	section := p.DefSection
+	// first defined line of the keys, use for check duplicate keys.
+	var keyLines map[string]int
+	if p.Strict || p.DupPolicy != DupLastWins {
+		keyLines = make(map[string]int)
+	}
 
 	// scan and parsing
 	for ts.Scan() {
@@ -236,6 +395,10 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 
 		if tok.Kind() == TokSection {
 			section = tok.Value()
+			p.addPosition(PosKey{Section: p.fmtName(section)}, ts.Line(), false)
+			if st, ok := tok.(*SectionToken); ok && st.Parent != "" {
+				p.parents[p.fmtName(section)] = p.fmtName(st.Parent)
+			}
 
 			// collect comments
 			if textscan.IsKindToken(textscan.TokComments, ts.PrevToken()) {
@@ -244,9 +407,22 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 			continue
 		}
 
+		// include directive
+		if tok.Kind() == TokInclude {
+			inc := Include{Section: p.fmtName(section), Path: tok.Value(), Line: ts.Line()}
+			p.includes = append(p.includes, inc)
+
+			if p.Includer != nil {
+				if err = p.Includer(inc); err != nil {
+					return
+				}
+			}
+			continue
+		}
+
 		// collect value
 		if tok.Kind() == textscan.TokValue {
-			vt := tok.(*textscan.ValueToken)
+			vt := tok.(*ValueToken)
 
 			var isSli bool
 			key := vt.Key()
@@ -255,6 +431,10 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 			if strings.HasSuffix(key, "[]") {
 				// skip parse array on lite mode
 				if p.ParseMode == ModeLite {
+					if p.Strict {
+						err = p.newSyntaxError(pm.line, pm.text, "array value is not supported in lite mode")
+						return
+					}
 					continue
 				}
 
@@ -262,7 +442,33 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 				isSli = true
 			}
 
+			// check duplicate key by DupPolicy
+			if keyLines != nil && !isSli {
+				name := p.fmtName(section) + "_" + p.fmtName(key)
+				if line, ok := keyLines[name]; ok {
+					switch {
+					case p.Strict || p.DupPolicy == DupError:
+						reason := fmt.Sprintf("duplicate key %q in section %q, first defined at line %d", key, section, line)
+						err = p.newSyntaxError(pm.line, pm.text, reason)
+						return
+					case p.DupPolicy == DupFirstWins:
+						continue
+					case p.DupPolicy == DupCollect:
+						// the lite mode data can only store string value
+						if p.ParseMode == ModeLite {
+							reason := fmt.Sprintf("duplicate key %q in section %q, collect as array is not supported in lite mode", key, section)
+							err = p.newSyntaxError(pm.line, pm.text, reason)
+							return
+						}
+						isSli = true
+					}
+				} else {
+					keyLines[name] = pm.line
+				}
+			}
+
 			p.collectValue(section, key, vt.Value(), isSli)
+			p.addPosition(PosKey{Section: p.fmtName(section), Key: p.fmtName(key)}, pm.line, !isSli)
 			if vt.HasComment() {
 				p.comments[section+"_"+key] = vt.Comment()
 			}
@@ -270,7 +476,7 @@ func (p *Parser) ParseFrom(in *bufio.Scanner) (count int64, err error) {
 	}
 
 	count = 0
-	err = ts.Err()
+	err = p.wrapScanErr(ts.Err())
 	return
 }
 
@@ -393,6 +599,41 @@ func (p *Parser) MapStruct(ptr any) (err error) {
 // Comments get all comments
 func (p *Parser) Comments() map[string]string { return p.comments }
 
+// Positions get the source positions of the sections and keys.
+//
+//   - key position: PosKey{Section: section, Key: key} => Position
+//   - section position: PosKey{Section: section} => Position
+//
+// The position of the last defined line will be used on the duplicate keys,
+// and the first on the array values or the duplicate sections.
+func (p *Parser) Positions() map[PosKey]Position { return p.positions }
+
+// record the position of the name, keep the first position on override=false
+func (p *Parser) addPosition(name PosKey, line int, override bool) {
+	if _, ok := p.positions[name]; ok && !override {
+		return
+	}
+	p.positions[name] = Position{File: p.FileName, Line: line}
+}
+
+// Parents get section inheritance map, key is child name, value is parent name.
+// only available on Options.ParseInherit=true
+func (p *Parser) Parents() map[string]string { return p.parents }
+
+// format section name by options
+func (p *Parser) fmtName(name string) string {
+	if p.IgnoreCase {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// Includes get the parsed include directives. only available on Options.ParseInclude=true
+func (p *Parser) Includes() []Include { return p.includes }
+
+// Document get the parsed document model. only available on Options.KeepDocument=true
+func (p *Parser) Document() *Document { return p.doc }
+
 // ParsedData get parsed data
 func (p *Parser) ParsedData() any {
 	if p.ParseMode == ModeFull {
@@ -424,7 +665,11 @@ func (p *Parser) LiteSection(name string) map[string]string {
 // Reset parser, clear parsed data
 func (p *Parser) Reset() {
 	// p.parsed = false
+	p.doc = nil
+	p.includes = nil
+	p.parents = make(map[string]string)
 	p.comments = make(map[string]string)
+	p.positions = make(map[PosKey]Position)
 	if p.ParseMode == ModeFull {
 		p.fullData = make(map[string]any)
 	} else {
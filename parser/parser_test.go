@@ -8,7 +8,6 @@ import (
 
 	"github.com/gookit/goutil/dump"
 	"github.com/gookit/goutil/maputil"
-	"github.com/gookit/goutil/strutil/textscan"
 	"github.com/gookit/goutil/testutil/assert"
 )
 
@@ -157,9 +156,9 @@ func TestNewSimpled(t *testing.T) {
 
 	err := p.ParseString("invalid string")
 	is.Err(err)
-	is.IsType(textscan.ErrScan{}, err)
+	is.IsType(&SyntaxError{}, err)
 	// is.Contains(err.Error(), "invalid syntax, no matcher available")
-	is.Contains(err.Error(), `line 1: "invalid string"`)
+	is.Contains(err.Error(), `line 1, column 1: invalid syntax, no matcher available: "invalid string"`)
 
 	err = p.ParseString("")
 	is.NoErr(err)
@@ -319,3 +318,317 @@ url_value1=https://github.com/inhere
 	assert.Eq(t, "http://127.0.0.1:9090", sMap.Str("url_ip_port"))
 	assert.Eq(t, "https://github.com/inhere", sMap.Str("url_value1"))
 }
+
+func TestParser_ParseInherit(t *testing.T) {
+	text := `
+[production]
+db_host = 10.0.0.1
+[staging : production]
+db_host = 127.0.0.1
+[ dev:staging ]
+`
+	p := NewLite(ParseInherit)
+	err := p.ParseString(text)
+	assert.NoErr(t, err)
+	assert.Eq(t, map[string]string{"staging": "production", "dev": "staging"}, p.Parents())
+	assert.Eq(t, "127.0.0.1", p.LiteData()["staging"]["db_host"])
+	assert.NotContainsKey(t, p.LiteData(), "staging : production")
+
+	// not enabled
+	p = NewLite()
+	err = p.ParseString(text)
+	assert.NoErr(t, err)
+	assert.Empty(t, p.Parents())
+	assert.ContainsKey(t, p.LiteData(), "staging : production")
+
+	name, parent := SplitInherit("a : b")
+	assert.Eq(t, "a", name)
+	assert.Eq(t, "b", parent)
+	name, parent = SplitInherit("a")
+	assert.Eq(t, "a", name)
+	assert.Eq(t, "", parent)
+}
+
+func TestParser_ParseInclude(t *testing.T) {
+	text := `
+include = base.ini
+!include "conf.d/*.ini"
+includes = val
+[sec]
+include=sec.ini
+`
+	p := NewLite(ParseInclude)
+	err := p.ParseString(text)
+	assert.NoErr(t, err)
+	assert.Eq(t, []Include{
+		{Section: DefSection, Path: "base.ini", Line: 2},
+		{Section: DefSection, Path: "conf.d/*.ini", Line: 3},
+		{Section: "sec", Path: "sec.ini", Line: 6},
+	}, p.Includes())
+	assert.Eq(t, "val", p.LiteSection(DefSection)["includes"])
+
+	// with includer
+	var paths []string
+	p = NewLite(ParseInclude, func(opt *Options) {
+		opt.Includer = func(inc Include) error {
+			paths = append(paths, inc.Path)
+			return nil
+		}
+	})
+	assert.NoErr(t, p.ParseString(text))
+	assert.Eq(t, []string{"base.ini", "conf.d/*.ini", "sec.ini"}, paths)
+
+	// not enabled
+	p = NewLite()
+	assert.NoErr(t, p.ParseString("include = base.ini"))
+	assert.Empty(t, p.Includes())
+	assert.Eq(t, "base.ini", p.LiteSection(DefSection)["include"])
+}
+
+func TestParser_SyntaxError(t *testing.T) {
+	is := assert.New(t)
+
+	p := New(WithFileName("app.ini"))
+	err := p.ParseString("name = inhere\n  invalid line")
+	is.Err(err)
+
+	se, ok := err.(*SyntaxError)
+	is.True(ok)
+	is.Eq("app.ini", se.File)
+	is.Eq(2, se.Line)
+	is.Eq(3, se.Column)
+	is.Eq("  invalid line", se.Text)
+	is.Eq("invalid syntax, no matcher available", se.Reason)
+	is.Eq(`ini: app.ini: line 2, column 3: invalid syntax, no matcher available: "  invalid line"`, err.Error())
+
+	// non-strict mode
+	p = New()
+	is.NoErr(p.ParseString("[]\nkey = val\nkey = val1\ntags[] = a\n"))
+	is.Eq("val1", p.LiteSection("")["key"])
+}
+
+func TestParser_Strict(t *testing.T) {
+	tests := []struct {
+		text   string
+		line   int
+		reason string
+	}{
+		{"a = 1\n[ ]\nb = 2", 2, "section name cannot be empty"},
+		{"a = 1\n[sec]\nb = 2\n  b = 3", 4, `duplicate key "b" in section "sec", first defined at line 3`},
+		{"tags[] = a", 1, "array value is not supported in lite mode"},
+		{"my key = val", 1, `invalid key "my key", cannot contain spaces`},
+		{"key] = val", 1, `invalid key "key]"`},
+	}
+
+	for _, tt := range tests {
+		p := New(Strict)
+		err := p.ParseString(tt.text)
+		assert.Err(t, err)
+
+		se, ok := err.(*SyntaxError)
+		assert.True(t, ok)
+		assert.Eq(t, tt.line, se.Line)
+		assert.Eq(t, tt.reason, se.Reason)
+	}
+
+	// full mode allow array and sub-map key
+	p := New(Strict, WithParseMode(ModeFull))
+	assert.NoErr(t, p.ParseString("tags[] = a\ntags[] = b\nusers[tom] = 23\n[sec]\ntags[] = c"))
+	assert.Eq(t, []string{"a", "b"}, p.FullData()[DefSection].(map[string]any)["tags"])
+}
+
+func TestParser_DupPolicy(t *testing.T) {
+	is := assert.New(t)
+	text := "name = inhere\n[sec]\nkey = a\n; comments\nkey = b\nkey = c"
+
+	p := New(WithDupPolicy(DupLastWins))
+	is.NoErr(p.ParseString(text))
+	is.Eq("c", p.LiteSection("sec")["key"])
+
+	p = New(WithDupPolicy(DupFirstWins))
+	is.NoErr(p.ParseString(text))
+	is.Eq("a", p.LiteSection("sec")["key"])
+
+	p = New(WithDupPolicy(DupError))
+	err := p.ParseString(text)
+	is.ErrMsg(err, `ini: line 5, column 1: duplicate key "key" in section "sec", first defined at line 3: "key = b"`)
+
+	p = New(WithDupPolicy(DupCollect), WithParseMode(ModeFull))
+	is.NoErr(p.ParseString(text))
+	is.Eq([]string{"a", "b", "c"}, p.FullData()["sec"].(map[string]any)["key"])
+	is.Eq("inhere", p.FullData()[DefSection].(map[string]any)["name"])
+
+	// lite mode cannot collect as array
+	p = New(WithDupPolicy(DupCollect))
+	err = p.ParseString(text)
+	is.ErrMsg(err, `ini: line 5, column 1: duplicate key "key" in section "sec", collect as array is not supported in lite mode: "key = b"`)
+
+	is.Eq("collect-as-array", DupCollect.String())
+	is.Eq("unknown", DupPolicy(10).String())
+}
+
+func TestParser_multiLineValue_more(t *testing.T) {
+	is := assert.New(t)
+	p := New()
+	err := p.ParseString(`
+single = """abc"""
+empty = """"""
+cert = """
+-----BEGIN-----
+  MIIB
+-----END-----
+"""
+sql = '''select * from "users"
+where id = 1'''
+desc = this is a \
+  long \
+  description
+path = C:\dir
+`)
+	is.NoErr(err)
+	mp := p.LiteSection(DefSection)
+	is.Eq("abc", mp["single"])
+	is.Eq("", mp["empty"])
+	is.Eq("\n-----BEGIN-----\n  MIIB\n-----END-----\n", mp["cert"])
+	is.Eq("select * from \"users\"\nwhere id = 1", mp["sql"])
+	is.Eq("this is a long description", mp["desc"])
+	is.Eq(`C:\dir`, mp["path"])
+
+	err = p.ParseString("key = \"\"\"abc\nno end")
+	is.ErrSubMsg(err, "not end of multi line value")
+
+	// indented continuation
+	p = New(IndentContinue)
+	err = p.ParseString(`
+desc = first line
+  second line
+  ; comments
+    third line
+name = inhere
+[sec]
+  key = val
+    more
+other =
+  a
+  b
+
+[sec1]
+key = val
+`)
+	is.NoErr(err)
+	is.Eq("first line\nsecond line\nthird line", p.LiteSection(DefSection)["desc"])
+	is.Eq("inhere", p.LiteSection(DefSection)["name"])
+	is.Eq("val\nmore", p.LiteSection("sec")["key"])
+	is.Eq("\na\nb", p.LiteSection("sec")["other"])
+	is.Eq("val", p.LiteSection("sec1")["key"])
+
+	// not enabled
+	p = New()
+	err = p.ParseString("desc = first line\n  second line")
+	is.Err(err)
+}
+
+func TestSplitQuoted(t *testing.T) {
+	tests := []struct {
+		in, str, rest string
+		ok            bool
+	}{
+		{`"a\tb\"c\\d\u4e2d"`, "a\tb\"c\\d中", "", true},
+		{`"a\nb" # comments`, "a\nb", " # comments", true},
+		{`"C:\dir\file"`, `C:\dir\file`, "", true},
+		{`"bad \u12"`, `bad \u12`, "", true},
+		{`'a\tb "c"'`, `a\tb "c"`, "", true},
+		{`"not closed`, "", "", false},
+		{`'not closed`, "", "", false},
+		{`abc`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		str, rest, ok := SplitQuoted(tt.in)
+		assert.Eq(t, tt.ok, ok, tt.in)
+		assert.Eq(t, tt.str, str, tt.in)
+		assert.Eq(t, tt.rest, rest, tt.in)
+	}
+}
+
+func TestParser_quotedValue(t *testing.T) {
+	is := assert.New(t)
+	text := `
+dq = "tab\there \"quoted\" back\\slash \u00e9"
+sq = 'literal \t "x"'
+sp = "  spaces  "
+raw = "x" # not comment
+cmt = "a # b" # comment
+`
+	p := New()
+	is.NoErr(p.ParseString(text))
+	mp := p.LiteSection(DefSection)
+	is.Eq("tab\there \"quoted\" back\\slash é", mp["dq"])
+	is.Eq(`literal \t "x"`, mp["sq"])
+	is.Eq("  spaces  ", mp["sp"])
+	is.Eq(`"x" # not comment`, mp["raw"])
+
+	p = New(InlineComment)
+	is.NoErr(p.ParseString(text))
+	mp = p.LiteSection(DefSection)
+	is.Eq("x", mp["raw"])
+	is.Eq("a # b", mp["cmt"])
+	is.Eq("# comment", p.Comments()[DefSection+"_cmt"])
+}
+
+func TestParser_MapStruct_defaultRequired(t *testing.T) {
+	is := assert.New(t)
+
+	type myConf struct {
+		Name string `ini:"name" required:"true"`
+		Env  string `ini:"env" default:"dev"`
+		Db   struct {
+			Host string `ini:"host" required:"true"`
+			Port int    `ini:"port" default:"3306"`
+		} `ini:"db"`
+	}
+
+	p := NewLite()
+	is.NoErr(p.ParseString(`
+[db]
+port = 3307
+`))
+
+	st := &myConf{}
+	err := p.MapStruct(st)
+	is.ErrMsg(err, "ini: missing required keys: name, db.host")
+	is.Eq("dev", st.Env)
+	is.Eq(3307, st.Db.Port)
+
+	p.Reset()
+	is.NoErr(p.ParseString("name = app\n[db]\nhost = localhost"))
+	st = &myConf{}
+	is.NoErr(p.MapStruct(st))
+	is.Eq("app", st.Name)
+	is.Eq(3306, st.Db.Port)
+}
+
+func TestParser_Positions(t *testing.T) {
+	is := assert.New(t)
+
+	p := New(WithParseMode(ModeFull), WithFileName("app.ini"))
+	is.NoErr(p.ParseString(`name = inhere
+arr[] = a
+arr[] = b
+
+[db]
+host = localhost
+host = 127.0.0.1
+`))
+
+	pos := p.Positions()
+	is.Eq(Position{File: "app.ini", Line: 1}, pos[PosKey{Section: "__default", Key: "name"}])
+	is.Eq(Position{File: "app.ini", Line: 2}, pos[PosKey{Section: "__default", Key: "arr"}])
+	is.Eq(Position{File: "app.ini", Line: 5}, pos[PosKey{Section: "db"}])
+	is.Eq(Position{File: "app.ini", Line: 7}, pos[PosKey{Section: "db", Key: "host"}])
+	is.Eq("app.ini:7", pos[PosKey{Section: "db", Key: "host"}].String())
+	is.Eq("line 3", Position{Line: 3}.String())
+
+	p.Reset()
+	is.Empty(p.Positions())
+}
@@ -0,0 +1,327 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gookit/goutil/strutil"
+	"github.com/gookit/goutil/strutil/textscan"
+)
+
+// multi line value marks
+const (
+	// MultiLineMarkS mark for multi line value. eg: key = '''...'''
+	MultiLineMarkS = "'''"
+	// MultiLineMarkQ mark at the line end, the next line will be joined without newline.
+	MultiLineMarkQ = `\`
+	// indented continuation lines, only on Options.IndentContinue=true
+	multiLineMarkIndent = "indent"
+)
+
+// errMultiLineNotEnd error
+var errMultiLineNotEnd = errors.New("not end of multi line value")
+
+// ValueMatcher match key-value line: "key = value". support multi line values:
+//
+//   - triple quotes: `key = """..."""`, or use triple single quotes. value is the exact text between the delimiters.
+//   - backslash continuation: `key = abc \`, the next line will be joined without newline.
+//   - indented continuation: the next lines indented deeper than the key line will be joined with newline.
+type ValueMatcher struct {
+	// InlineComment parse and split inline comment
+	InlineComment bool
+	// IndentContinue allow indented continuation lines, like Python configparser.
+	IndentContinue bool
+	// KeyCheckFn check the key string is valid
+	KeyCheckFn func(key string) error
+}
+
+// Match key-value line
+func (m *ValueMatcher) Match(text string, prev textscan.Token) (textscan.Token, error) {
+	pos := strings.IndexByte(text, '=')
+	if pos < 0 {
+		return nil, nil
+	}
+
+	key := strings.TrimSpace(text[:pos])
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	if m.KeyCheckFn != nil {
+		if err := m.KeyCheckFn(key); err != nil {
+			return nil, err
+		}
+	}
+
+	tok := &ValueToken{key: key}
+	// merge previous comments token
+	if textscan.IsKindToken(textscan.TokComments, prev) {
+		tok.comment = prev.Value()
+	}
+
+	// NOTE: keep the right spaces for multi line value
+	raw := strings.TrimLeft(text[pos+1:], " \t")
+	val := strings.TrimRight(raw, " \t")
+
+	// triple quotes multi line value
+	if len(val) > 2 && (strings.HasPrefix(val, MultiLineMark) || strings.HasPrefix(val, MultiLineMarkS)) {
+		mark, body := val[:3], raw[3:]
+
+		// end at the line. eg: key = """abc"""
+		if end := strings.TrimRight(body, " \t"); len(end) > 2 && strings.HasSuffix(end, mark) {
+			tok.value = end[:len(end)-3]
+			return tok, nil
+		}
+
+		tok.mark = mark
+		tok.values = []string{body}
+		return tok, nil
+	}
+
+	// backslash continuation
+	if strings.HasSuffix(val, MultiLineMarkQ) {
+		tok.mark = MultiLineMarkQ
+		tok.value = val[:len(val)-1]
+		return tok, nil
+	}
+
+	if val != "" {
+		val = m.inlineCommentAndUnquote(tok, val)
+	}
+
+	tok.value = val
+	if m.IndentContinue {
+		tok.mark = multiLineMarkIndent
+		tok.indent = indentWidth(text)
+	}
+	return tok, nil
+}
+
+func (m *ValueMatcher) inlineCommentAndUnquote(tok *ValueToken, val string) string {
+	// quoted value. eg: "val" OR 'val'
+	if val[0] == '"' || val[0] == '\'' {
+		if str, rest, ok := SplitQuoted(val); ok {
+			rest = strings.TrimSpace(rest)
+			if rest == "" {
+				return str
+			}
+
+			if m.InlineComment && (IsCommentChar(rest[0]) || strings.HasPrefix(rest, "//")) {
+				tok.addComment(rest)
+				return str
+			}
+		}
+	}
+
+	if m.InlineComment {
+		var comment string
+		val, comment = strutil.SplitInlineComment(val, true)
+		tok.addComment(comment)
+	}
+	return val
+}
+
+// SplitQuoted split the quoted string at start of the s, returns the unquoted string and the rest text.
+//
+// Quoting rules:
+//
+//   - double-quoted: support escape sequences \t \n \r \" \' \\ \uXXXX, unknown sequence will be kept.
+//   - single-quoted: literal string, no escape sequences.
+//
+// ok is false on the s is not start with quote or the quote is not closed.
+func SplitQuoted(s string) (str, rest string, ok bool) {
+	if len(s) < 2 {
+		return
+	}
+
+	// single-quoted literal string
+	if s[0] == '\'' {
+		if end := strings.IndexByte(s[1:], '\''); end > -1 {
+			return s[1 : end+1], s[end+2:], true
+		}
+		return
+	}
+
+	if s[0] != '"' {
+		return
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			return sb.String(), s[i+1:], true
+		}
+
+		if c != '\\' || i == len(s)-1 {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case '"', '\'', '\\':
+			sb.WriteByte(s[i])
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteString(s[i-1 : i+1])
+		default: // keep unknown escape sequence
+			sb.WriteString(s[i-1 : i+1])
+		}
+	}
+	return "", "", false
+}
+
+// ValueToken for key-value line, contains key, value and comments.
+type ValueToken struct {
+	key   string
+	value string
+	// comments of the key
+	comment string
+	// multi line value mark: `"""`, `'''`, `\` or indent
+	mark string
+	// indent width of the key line, for indented continuation
+	indent int
+	// lines of the triple quotes multi line value
+	values []string
+}
+
+// Kind of token
+func (t *ValueToken) Kind() textscan.Kind { return textscan.TokValue }
+
+// IsValid token
+func (t *ValueToken) IsValid() bool { return true }
+
+// Key name
+func (t *ValueToken) Key() string { return t.key }
+
+// Value text string
+func (t *ValueToken) Value() string {
+	if len(t.values) > 0 {
+		return strings.Join(t.values, "\n")
+	}
+	return t.value
+}
+
+// Comment lines string
+func (t *ValueToken) Comment() string { return t.comment }
+
+func (t *ValueToken) addComment(comment string) {
+	if comment == "" {
+		return
+	}
+
+	if t.comment != "" {
+		t.comment += "\n" + comment
+	} else {
+		t.comment = comment
+	}
+}
+
+// HasComment for the value
+func (t *ValueToken) HasComment() bool { return t.comment != "" }
+
+// HasMore is multi line value
+func (t *ValueToken) HasMore() bool { return t.mark != "" }
+
+// MergeSame not allowed
+func (t *ValueToken) MergeSame(_ textscan.Token) error {
+	return errors.New("merge value token not allowed")
+}
+
+// String of token
+func (t *ValueToken) String() string {
+	return fmt.Sprintf("key: %s\nvalue: %q\ncomments: %s", t.key, t.Value(), t.comment)
+}
+
+// ScanMore scan multi line value
+func (t *ValueToken) ScanMore(ts *textscan.TextScanner) error {
+	switch t.mark {
+	case MultiLineMark, MultiLineMarkS:
+		for {
+			ok, line := ts.ScanNext()
+			if !ok {
+				return errMultiLineNotEnd
+			}
+
+			// end line. eg: `abc"""`
+			str := strings.TrimRight(line, " \t")
+			if strings.HasSuffix(str, t.mark) {
+				t.values = append(t.values, str[:len(str)-3])
+				return nil
+			}
+			t.values = append(t.values, line)
+		}
+	case MultiLineMarkQ:
+		for {
+			ok, line := ts.ScanNext()
+			if !ok {
+				return nil
+			}
+
+			str := strings.TrimSpace(line)
+			if !strings.HasSuffix(str, MultiLineMarkQ) {
+				t.value += str
+				return nil
+			}
+			t.value += str[:len(str)-1]
+		}
+	case multiLineMarkIndent:
+		for {
+			ok, line := ts.ScanNext()
+			if !ok {
+				return nil
+			}
+
+			str := strings.TrimSpace(line)
+			if str == "" {
+				return nil
+			}
+
+			// not continuation line, give it back to the scanner.
+			if indentWidth(line) <= t.indent {
+				ts.SetNext(line)
+				return nil
+			}
+
+			// skip comments line
+			if !IsCommentChar(str[0]) {
+				t.value += "\n" + str
+			}
+		}
+	}
+	return nil
+}
+
+// width of the leading spaces
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// nextResetter reset the next text of the scanner on match each line.
+//
+// TIP: textscan.TextScanner will not reset the next text after used it,
+// this matcher must be the first matcher.
+type nextResetter struct {
+	ts *textscan.TextScanner
+}
+
+// Match always returns nil
+func (m *nextResetter) Match(_ string, _ textscan.Token) (textscan.Token, error) {
+	m.ts.SetNext("")
+	return nil, nil
+}
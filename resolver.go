@@ -0,0 +1,160 @@
+package ini
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/gookit/goutil/envutil"
+)
+
+// Resolver resolve the argument of the expression "${scheme:arg}" to value.
+type Resolver func(arg string) (string, error)
+
+// resolver expression. eg: "${env:HOME}", "${file:/run/secrets/db_pass}"
+var resolverRegex = regexp.MustCompile(`\$\{([a-zA-Z][\w-]*):([^{}]*)\}`)
+
+// builtin resolvers, the "cmd" resolver is not builtin, it needs register by CmdResolver.
+var builtinResolvers = map[string]Resolver{
+	"env":    EnvResolver,
+	"file":   FileResolver,
+	"base64": Base64Resolver,
+}
+
+// ResolveError error on resolve the expression by resolver
+type ResolveError struct {
+	// Expr the expression. eg: "${file:/run/secrets/db_pass}"
+	Expr string
+	Err  error
+}
+
+// Error message
+func (e *ResolveError) Error() string {
+	return fmt.Sprintf("ini: resolve %s: %s", e.Expr, e.Err.Error())
+}
+
+// Unwrap the real error
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// RegisterResolver register resolver for the default instance
+func RegisterResolver(scheme string, fn Resolver) { dc.RegisterResolver(scheme, fn) }
+
+// RegisterResolver register a resolver by scheme, it will override the exists resolver.
+// The expressions like "${scheme:arg}" will be resolved on get value, need Options.ParseResolver=true.
+//
+// Builtin resolvers: env, file, base64. The "cmd" is opt-in, register it by:
+//
+//	cfg.RegisterResolver("cmd", ini.CmdResolver)
+func (c *Ini) RegisterResolver(scheme string, fn Resolver) {
+	c.lock.Lock()
+	defer c.unlock()
+
+	if c.resolvers == nil {
+		c.resolvers = make(map[string]Resolver)
+	}
+	c.resolvers[scheme] = fn
+	c.changed = true
+}
+
+// get resolver by scheme, returns nil on not found.
+func (c *Ini) resolver(scheme string) Resolver {
+	if fn, ok := c.resolvers[scheme]; ok {
+		return fn
+	}
+	return builtinResolvers[scheme]
+}
+
+// resolve the resolver expressions in the value, the unknown schemes will be kept.
+func (c *Ini) resolveValue(val string) (string, error) {
+	if !strings.Contains(val, "${") {
+		return val, nil
+	}
+
+	var err error
+	val = resolverRegex.ReplaceAllStringFunc(val, func(expr string) string {
+		ss := resolverRegex.FindStringSubmatch(expr)
+		fn := c.resolver(ss[1])
+		if fn == nil || err != nil {
+			return expr
+		}
+
+		str, fnErr := fn(ss[2])
+		if fnErr != nil {
+			err = &ResolveError{Expr: expr, Err: fnErr}
+			return expr
+		}
+		return str
+	})
+	return val, err
+}
+
+// parse ENV in the value on load, the expressions will be resolved on get value.
+func (c *Ini) parseEnv(val string) string {
+	parse := envutil.ParseValue
+	if c.opts.ExtendedVar {
+		// the "${name}" will be resolved on get value, only parse the ENV with default value here.
+		parse = func(s string) string {
+			return envDefRegex.ReplaceAllStringFunc(s, envutil.ParseValue)
+		}
+	}
+
+	if !c.opts.ParseResolver {
+		return parse(val)
+	}
+
+	// keep the resolver expressions
+	var sb strings.Builder
+	var last int
+	for _, loc := range resolverRegex.FindAllStringIndex(val, -1) {
+		sb.WriteString(parse(val[last:loc[0]]))
+		sb.WriteString(val[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(parse(val[last:]))
+	return sb.String()
+}
+
+// EnvResolver get ENV value by name. eg: "${env:HOME}"
+func EnvResolver(name string) (string, error) {
+	return os.Getenv(name), nil
+}
+
+// FileResolver read the file contents, the trailing newline will be trimmed. eg: "${file:/run/secrets/db_pass}"
+func FileResolver(path string) (string, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(bs), "\r\n"), nil
+}
+
+// Base64Resolver decode the base64 string. eg: "${base64:aW5oZXJl}"
+func Base64Resolver(str string) (string, error) {
+	bs, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// CmdResolver run the command and returns the output, the trailing newline will be trimmed.
+// eg: "${cmd:cat /run/secrets/token}"
+//
+// NOTICE: it is not builtin for security, the command will be run on each get value.
+// The command will not run in a shell, the arguments are split by spaces.
+func CmdResolver(cmdline string) (string, error) {
+	args := strings.Fields(cmdline)
+	if len(args) == 0 {
+		return "", errors.New("command cannot be empty")
+	}
+
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,106 @@
+package ini_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_ParseResolver(t *testing.T) {
+	is := assert.New(t)
+	is.NoErr(os.Setenv("INI_TEST_RESOLVER", "env-val"))
+	defer os.Unsetenv("INI_TEST_RESOLVER")
+
+	secret := filepath.Join(t.TempDir(), "db_pass")
+	is.NoErr(os.WriteFile(secret, []byte("s3cret\n"), 0600))
+
+	cfg := ini.NewWithOptions(ini.ParseResolver)
+	err := cfg.LoadStrings(`
+env = ${env:INI_TEST_RESOLVER}
+plain = ${INI_TEST_RESOLVER}
+b64 = ${base64:aW5oZXJl}
+unknown = ${unknown:val}
+[db]
+pass = ${file:` + secret + `}
+dsn = user:${file:` + secret + `}@tcp(${vault:db/host})
+`)
+	is.NoErr(err)
+
+	is.Eq("env-val", cfg.Get("env"))
+	is.Eq("env-val", cfg.Get("plain"))
+	is.Eq("inhere", cfg.Get("b64"))
+	is.Eq("${unknown:val}", cfg.Get("unknown"))
+	is.Eq("s3cret", cfg.Get("db.pass"))
+
+	// evaluated lazily on get value
+	is.NoErr(os.WriteFile(secret, []byte("new-pass"), 0600))
+	is.Eq("new-pass", cfg.Get("db.pass"))
+	is.Eq("new-pass", cfg.StringMap("db")["pass"])
+
+	// custom resolver
+	is.Eq("user:new-pass@tcp(${vault:db/host})", cfg.Get("db.dsn"))
+	cfg.RegisterResolver("vault", func(arg string) (string, error) {
+		if arg == "db/host" {
+			return "127.0.0.1:3306", nil
+		}
+		return "", errors.New("secret not found")
+	})
+	is.Eq("user:new-pass@tcp(127.0.0.1:3306)", cfg.Get("db.dsn"))
+
+	// error
+	is.NoErr(os.Remove(secret))
+	_, err = cfg.GetString("db.pass")
+	var re *ini.ResolveError
+	is.True(errors.As(err, &re))
+	is.Eq("${file:"+secret+"}", re.Expr)
+	is.True(errors.Is(err, os.ErrNotExist))
+
+	is.Eq("${file:"+secret+"}", cfg.Get("db.pass"))
+	is.ErrSubMsg(cfg.Error(), "ini: resolve ${file:")
+
+	// without ParseResolver
+	cfg = ini.New()
+	is.NoErr(cfg.LoadStrings("b64 = ${base64:aW5oZXJl}"))
+	is.Eq("", cfg.Get("b64"))
+}
+
+func TestIni_ParseResolver_withVar(t *testing.T) {
+	is := assert.New(t)
+
+	cfg := ini.NewWithOptions(ini.ParseResolver, ini.ExtendedVar)
+	cfg.RegisterResolver("upper", func(arg string) (string, error) {
+		return strings.ToUpper(arg), nil
+	})
+
+	err := cfg.LoadStrings(`
+name = ${upper:inhere}
+[app]
+title = ${name} ${base64:YXBw}
+`)
+	is.NoErr(err)
+	is.Eq("INHERE", cfg.Get("name"))
+	is.Eq("INHERE app", cfg.Get("app.title"))
+}
+
+func TestCmdResolver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skip on windows")
+	}
+
+	is := assert.New(t)
+	cfg := ini.NewWithOptions(ini.ParseResolver)
+	is.NoErr(cfg.LoadStrings("token = ${cmd:echo abc}"))
+	is.Eq("${cmd:echo abc}", cfg.Get("token"))
+
+	cfg.RegisterResolver("cmd", ini.CmdResolver)
+	is.Eq("abc", cfg.Get("token"))
+
+	_, err := ini.CmdResolver(" ")
+	is.ErrMsg(err, "command cannot be empty")
+}
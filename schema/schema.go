@@ -0,0 +1,353 @@
+// Package schema provide declare a schema for INI config and validate the loaded data by it.
+//
+// Usage:
+//
+//	sch := &schema.Schema{
+//		Sections: []*schema.Section{
+//			{
+//				Name: "db",
+//				Keys: []*schema.Key{
+//					{Name: "host", Required: true},
+//					{Name: "port", Type: schema.Int, Range: &schema.Range{Min: 1, Max: 65535}},
+//					{Name: "driver", Enum: []string{"mysql", "sqlite"}},
+//				},
+//			},
+//		},
+//	}
+//
+//	err := sch.Validate(cfg)
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gookit/goutil/strutil"
+	"github.com/gookit/ini/v2"
+	"github.com/gookit/ini/v2/parser"
+)
+
+// Type of the value
+type Type uint8
+
+// value types
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Duration
+)
+
+// String type name
+func (t Type) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Duration:
+		return "duration"
+	}
+	return "unknown"
+}
+
+// Range of the numeric value, the Min and Max are inclusive.
+//
+// Use math.Inf(-1) or math.Inf(1) for open range.
+type Range struct {
+	Min, Max float64
+}
+
+// Key schema
+type Key struct {
+	// Name of the key
+	Name string
+	// Type of the value, default is String
+	Type Type
+	// Required the key must exist and not empty
+	Required bool
+	// Enum allowed values, empty is no limit
+	Enum []string
+	// Range of the numeric value(Int, Float), or the length of the String value.
+	Range *Range
+	// Pattern regexp pattern for match the value
+	Pattern string
+	// IsArray the key is array value. eg: "key[] = val"
+	IsArray bool
+
+	// the Pattern is compiled once, Validate can be called concurrently.
+	once     sync.Once
+	regex    *regexp.Regexp
+	regexErr error
+}
+
+// Section schema
+type Section struct {
+	// Name of the section, use ini.DefSection() for the default section
+	Name string
+	// Required the section must exist
+	Required bool
+	// AllowUnknown allow the keys not declared in the Keys
+	AllowUnknown bool
+	// Keys of the section
+	Keys []*Key
+}
+
+// Schema for validate INI config data
+type Schema struct {
+	// AllowUnknown allow the sections not declared in the Sections
+	AllowUnknown bool
+	// Sections of the config
+	Sections []*Section
+}
+
+// Violation of the schema
+type Violation struct {
+	// Section name
+	Section string
+	// Key name, is empty on the violation is for the section
+	Key string
+	// Pos position of the key or section, Line is 0 on unknown.
+	Pos parser.Position
+	// Msg violation message
+	Msg string
+}
+
+// String violation. eg: `app.ini:3: db.port: value "abc" is not a valid int`
+func (v Violation) String() string {
+	var sb strings.Builder
+	if v.Pos.Line > 0 {
+		sb.WriteString(v.Pos.String())
+		sb.WriteString(": ")
+	}
+
+	sb.WriteString(v.Section)
+	if v.Key != "" {
+		sb.WriteByte('.')
+		sb.WriteString(v.Key)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(v.Msg)
+	return sb.String()
+}
+
+// ValidationError contains all violations of the schema
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error message, one violation per line.
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = "  " + v.String()
+	}
+	return fmt.Sprintf("ini: schema validation failed, %d violation(s):\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// Validate the config data by schema, returns *ValidationError contains all violations.
+func (s *Schema) Validate(c *ini.Ini) error {
+	if err := s.compile(); err != nil {
+		return err
+	}
+
+	v := &validator{c: c, sep: c.Options().SectionSep}
+	declared := make(map[string]bool, len(s.Sections))
+	for _, sec := range s.Sections {
+		declared[sec.Name] = true
+		v.checkSection(sec)
+	}
+
+	if !s.AllowUnknown {
+		for _, name := range sortedKeys(c.Data()) {
+			if !declared[name] {
+				pos, _ := c.SectionPosition(name)
+				v.add(name, "", pos, "unknown section")
+			}
+		}
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// compile the regexp patterns
+func (s *Schema) compile() error {
+	for _, sec := range s.Sections {
+		for _, key := range sec.Keys {
+			if err := key.compile(); err != nil {
+				return fmt.Errorf("ini: invalid pattern of the key %s.%s: %w", sec.Name, key.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compile the Pattern only once
+func (k *Key) compile() error {
+	k.once.Do(func() {
+		if k.Pattern != "" {
+			k.regex, k.regexErr = regexp.Compile(k.Pattern)
+		}
+	})
+	return k.regexErr
+}
+
+type validator struct {
+	c   *ini.Ini
+	sep string
+
+	violations []Violation
+}
+
+func (v *validator) add(section, key string, pos parser.Position, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Section: section,
+		Key:     key,
+		Pos:     pos,
+		Msg:     fmt.Sprintf(format, args...),
+	})
+}
+
+// full key path for get value. the default section key has no section prefix.
+func (v *validator) keyPath(section, key string) string {
+	if section == v.c.DefSection() {
+		return key
+	}
+	return section + v.sep + key
+}
+
+func (v *validator) checkSection(sec *Section) {
+	pos, _ := v.c.SectionPosition(sec.Name)
+	if !v.c.HasSection(sec.Name) {
+		if sec.Required {
+			v.add(sec.Name, "", pos, "required section is missing")
+		}
+		return
+	}
+
+	data := v.c.StringMap(sec.Name)
+	declared := make(map[string]bool, len(sec.Keys))
+	for _, key := range sec.Keys {
+		declared[key.Name] = true
+		v.checkKey(sec.Name, key, data, pos)
+	}
+
+	if sec.AllowUnknown {
+		return
+	}
+
+	for _, name := range sortedKeys(data) {
+		if !declared[name] {
+			kPos, _ := v.c.KeyPosition(v.keyPath(sec.Name, name))
+			v.add(sec.Name, name, kPos, "unknown key")
+		}
+	}
+}
+
+// check the key value, the section position will be used on the key is missing.
+func (v *validator) checkKey(section string, key *Key, data map[string]string, secPos parser.Position) {
+	path := v.keyPath(section, key.Name)
+	pos, ok := v.c.KeyPosition(path)
+	if !ok {
+		pos = secPos
+	}
+
+	var values []string
+	if key.IsArray {
+		values = v.c.Slice(path)
+	} else if val, ok := data[key.Name]; ok && val != "" {
+		values = []string{val}
+	}
+
+	if len(values) == 0 {
+		if key.Required {
+			v.add(section, key.Name, pos, "required key is missing")
+		}
+		return
+	}
+
+	for _, val := range values {
+		if msg := key.check(val); msg != "" {
+			v.add(section, key.Name, pos, "value %q %s", val, msg)
+		}
+	}
+}
+
+// check the value, returns the violation message.
+func (k *Key) check(val string) string {
+	var num float64
+	switch k.Type {
+	case Int:
+		iv, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return "is not a valid int"
+		}
+		num = float64(iv)
+	case Float:
+		fv, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(fv) {
+			return "is not a valid float"
+		}
+		num = fv
+	case Bool:
+		if _, err := strutil.ToBool(val); err != nil {
+			return "is not a valid bool"
+		}
+	case Duration:
+		if _, err := time.ParseDuration(val); err != nil {
+			return "is not a valid duration"
+		}
+	default: // String
+		num = float64(len(val))
+	}
+
+	if len(k.Enum) > 0 && !inEnum(val, k.Enum) {
+		return "must be one of " + strings.Join(k.Enum, ", ")
+	}
+
+	if k.Range != nil && (k.Type == String || k.Type == Int || k.Type == Float) {
+		if num < k.Range.Min || num > k.Range.Max {
+			if k.Type == String {
+				return fmt.Sprintf("length must be in range [%v, %v]", k.Range.Min, k.Range.Max)
+			}
+			return fmt.Sprintf("must be in range [%v, %v]", k.Range.Min, k.Range.Max)
+		}
+	}
+
+	if k.regex != nil && !k.regex.MatchString(val) {
+		return "does not match pattern " + k.Pattern
+	}
+	return ""
+}
+
+func inEnum(val string, enum []string) bool {
+	for _, s := range enum {
+		if s == val {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](mp map[string]T) []string {
+	keys := make([]string, 0, len(mp))
+	for k := range mp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,155 @@
+package schema_test
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+	"github.com/gookit/ini/v2/schema"
+)
+
+var appSchema = &schema.Schema{
+	Sections: []*schema.Section{
+		{
+			Name: "__default",
+			Keys: []*schema.Key{
+				{Name: "name", Required: true, Range: &schema.Range{Min: 2, Max: 20}},
+				{Name: "debug", Type: schema.Bool},
+			},
+		},
+		{
+			Name:     "db",
+			Required: true,
+			Keys: []*schema.Key{
+				{Name: "host", Required: true, Pattern: `^[\w.-]+$`},
+				{Name: "port", Type: schema.Int, Range: &schema.Range{Min: 1, Max: 65535}},
+				{Name: "driver", Enum: []string{"mysql", "sqlite"}},
+				{Name: "timeout", Type: schema.Duration},
+				{Name: "ratio", Type: schema.Float, Range: &schema.Range{Min: 0, Max: math.Inf(1)}},
+			},
+		},
+		{
+			Name:         "cache",
+			AllowUnknown: true,
+			Keys: []*schema.Key{
+				{Name: "ports", Type: schema.Int, IsArray: true},
+			},
+		},
+	},
+}
+
+func TestSchema_Validate(t *testing.T) {
+	is := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte(`name = myApp
+debug = yes
+
+[db]
+host = localhost
+port = 3306
+driver = mysql
+timeout = 3s
+ratio = 0.5
+
+[cache]
+ports[] = 6379
+ports[] = 6380
+other = val
+`), 0644))
+
+	cfg := ini.New()
+	is.NoErr(cfg.LoadFiles(file))
+	is.NoErr(appSchema.Validate(cfg))
+}
+
+func TestSchema_Validate_violations(t *testing.T) {
+	is := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte(`debug = not-bool
+
+[db]
+host = local host
+port = 70000
+driver = pgsql
+timeout = 3
+ratio = -1
+user = admin
+
+[cache]
+ports[] = 6379
+ports[] = abc
+
+[unknown]
+key = val
+`), 0644))
+
+	cfg := ini.New()
+	is.NoErr(cfg.LoadFiles(file))
+
+	err := appSchema.Validate(cfg)
+	var ve *schema.ValidationError
+	is.True(errors.As(err, &ve))
+
+	ss := make([]string, len(ve.Violations))
+	for i, v := range ve.Violations {
+		ss[i] = v.String()
+	}
+
+	is.Eq([]string{
+		"__default.name: required key is missing",
+		file + `:1: __default.debug: value "not-bool" is not a valid bool`,
+		file + `:4: db.host: value "local host" does not match pattern ^[\w.-]+$`,
+		file + `:5: db.port: value "70000" must be in range [1, 65535]`,
+		file + `:6: db.driver: value "pgsql" must be one of mysql, sqlite`,
+		file + `:7: db.timeout: value "3" is not a valid duration`,
+		file + `:8: db.ratio: value "-1" must be in range [0, +Inf]`,
+		file + `:9: db.user: unknown key`,
+		file + `:12: cache.ports: value "abc" is not a valid int`,
+		file + `:15: unknown: unknown section`,
+	}, ss)
+	is.StrContains(err.Error(), "ini: schema validation failed, 10 violation(s):\n")
+
+	// required section
+	cfg = ini.New()
+	is.NoErr(cfg.LoadStrings("name = app"))
+	err = appSchema.Validate(cfg)
+	is.ErrMsg(err, "ini: schema validation failed, 1 violation(s):\n  db: required section is missing")
+
+	// invalid pattern
+	sch := &schema.Schema{Sections: []*schema.Section{
+		{Name: "db", Keys: []*schema.Key{{Name: "host", Pattern: "["}}},
+	}}
+	is.ErrSubMsg(sch.Validate(cfg), "ini: invalid pattern of the key db.host")
+}
+
+func TestSchema_Validate_concurrent(t *testing.T) {
+	sch := &schema.Schema{Sections: []*schema.Section{
+		{Name: "db", Keys: []*schema.Key{{Name: "host", Pattern: `^[\w.-]+$`}}},
+	}}
+
+	cfg := ini.New()
+	assert.NoErr(t, cfg.LoadStrings("[db]\nhost = localhost"))
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errCh <- sch.Validate(cfg)
+		}()
+	}
+	wg.Wait()
+
+	close(errCh)
+	for err := range errCh {
+		assert.NoErr(t, err)
+	}
+}
@@ -0,0 +1,262 @@
+package ini
+
+import (
+	"time"
+
+	"github.com/gookit/ini/v2/parser"
+)
+
+// Snapshot an immutable read-only view of the config data, it has the same getter API as Ini.
+//
+// The getters of a snapshot are lock-free, they never contend with the Set, Load* or Reload.
+// Get a snapshot by Ini.Snapshot(), please call it again to get the newest data.
+type Snapshot struct {
+	// the frozen copy of the data, read without lock.
+	c *Ini
+}
+
+// Snapshot get the read-only snapshot of current data.
+//
+// The first call will build the snapshot and enable it, then the write methods(Set, Load*, Reload...)
+// will build the new snapshot and publish it by an atomic pointer before release the write lock(copy-on-write).
+// So the calls are always lock-free, it is just an atomic load.
+//
+// NOTICE: after enabled, each write will copy the data, please avoid frequently write on the big data.
+//
+// Usage:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		cfg := conf.Snapshot()
+//		host := cfg.String("db.host")
+//		// ...
+//	}
+func (c *Ini) Snapshot() *Snapshot {
+	if s := c.snap.Load(); s != nil {
+		return s
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// other goroutine has enabled the snapshot
+	if s := c.snap.Load(); s != nil {
+		return s
+	}
+
+	s := c.newSnapshot()
+	c.snap.Store(s)
+	return s
+}
+
+// build a new snapshot by copy current data. must be called on hold the lock.
+func (c *Ini) newSnapshot() *Snapshot {
+	opts := *c.opts
+	opts.Readonly = true
+
+	fc := &Ini{
+		opts:      &opts,
+		frozen:    true,
+		varRegex:  c.varRegex,
+		data:      make(map[string]Section, len(c.data)),
+		resolvers: copyResolvers(c.resolvers),
+	}
+	for name, sec := range c.data {
+		fc.data[name] = copySection(sec)
+	}
+
+	if len(c.arrays) > 0 {
+		fc.arrays = make(map[string]map[string][]string, len(c.arrays))
+		for name, mp := range c.arrays {
+			arrMp := make(map[string][]string, len(mp))
+			for key, vals := range mp {
+				arrMp[key] = append([]string(nil), vals...)
+			}
+			fc.arrays[name] = arrMp
+		}
+	}
+
+	if len(c.parents) > 0 {
+		fc.parents = make(map[string]string, len(c.parents))
+		for child, parent := range c.parents {
+			fc.parents[child] = parent
+		}
+	}
+
+	if len(c.positions) > 0 {
+		fc.positions = make(map[parser.PosKey]parser.Position, len(c.positions))
+		for key, pos := range c.positions {
+			fc.positions[key] = pos
+		}
+	}
+
+	if c.order != nil {
+		fc.order = c.order.clone()
+	}
+	return &Snapshot{c: fc}
+}
+
+// unlock the write lock, will publish the new snapshot if the data is changed and the snapshot is enabled.
+func (c *Ini) unlock() {
+	if c.changed {
+		c.changed = false
+		c.publishSnapshot()
+	}
+	c.lock.Unlock()
+}
+
+// build and publish the new snapshot on it is enabled. must be called on hold the write lock.
+func (c *Ini) publishSnapshot() {
+	if c.snap.Load() != nil {
+		c.snap.Store(c.newSnapshot())
+	}
+}
+
+// read lock, the frozen data of snapshot is read without lock.
+func (c *Ini) rLock() {
+	if !c.frozen {
+		c.lock.RLock()
+	}
+}
+
+func (c *Ini) rUnlock() {
+	if !c.frozen {
+		c.lock.RUnlock()
+	}
+}
+
+func copyResolvers(src map[string]Resolver) map[string]Resolver {
+	if len(src) == 0 {
+		return nil
+	}
+
+	mp := make(map[string]Resolver, len(src))
+	for scheme, fn := range src {
+		mp[scheme] = fn
+	}
+	return mp
+}
+
+/*************************************************************
+ * getter methods of the snapshot
+ *************************************************************/
+
+// Options get options info of the snapshot, the Readonly is always true.
+func (s *Snapshot) Options() Options { return s.c.Options() }
+
+// HasKey check key exists
+func (s *Snapshot) HasKey(key string) bool { return s.c.HasKey(key) }
+
+// HasSection check section exists
+func (s *Snapshot) HasSection(name string) bool { return s.c.HasSection(name) }
+
+// GetValue get a value by key string. see Ini.GetValue
+func (s *Snapshot) GetValue(key string) (string, bool) { return s.c.GetValue(key) }
+
+// Get a value by key string. see Ini.Get
+func (s *Snapshot) Get(key string, defVal ...string) string { return s.c.Get(key, defVal...) }
+
+// String like Get method
+func (s *Snapshot) String(key string, defVal ...string) string { return s.c.String(key, defVal...) }
+
+// Int get an int value, if not found return default value
+func (s *Snapshot) Int(key string, defVal ...int) int { return s.c.Int(key, defVal...) }
+
+// Uint get an uint value, if not found return default value
+func (s *Snapshot) Uint(key string, defVal ...uint) uint { return s.c.Uint(key, defVal...) }
+
+// Int64 get an int64 value, if not found return default value
+func (s *Snapshot) Int64(key string, defVal ...int64) int64 { return s.c.Int64(key, defVal...) }
+
+// Bool get a bool value, if not found return default value
+func (s *Snapshot) Bool(key string, defVal ...bool) bool { return s.c.Bool(key, defVal...) }
+
+// Float64 get a float64 value, if not found or convert failed return default value
+func (s *Snapshot) Float64(key string, defVal ...float64) float64 {
+	return s.c.Float64(key, defVal...)
+}
+
+// Duration get a time.Duration value, if not found or convert failed return default value
+func (s *Snapshot) Duration(key string, defVal ...time.Duration) time.Duration {
+	return s.c.Duration(key, defVal...)
+}
+
+// ByteSize get a byte size value, if not found or convert failed return default value
+func (s *Snapshot) ByteSize(key string, defVal ...uint64) uint64 {
+	return s.c.ByteSize(key, defVal...)
+}
+
+// Time get a time.Time value by layout, if not found or convert failed return default value
+func (s *Snapshot) Time(key, layout string, defVal ...time.Time) time.Time {
+	return s.c.Time(key, layout, defVal...)
+}
+
+// Strings get a string array, by split a string
+func (s *Snapshot) Strings(key string, sep ...string) []string { return s.c.Strings(key, sep...) }
+
+// Slice get array values by key. see Ini.Slice
+func (s *Snapshot) Slice(key string) []string { return s.c.Slice(key) }
+
+// IntSlice get array values and convert to int slice
+func (s *Snapshot) IntSlice(key string) []int { return s.c.IntSlice(key) }
+
+// SubMap get map values by key, for the values like "key[sub] = val"
+func (s *Snapshot) SubMap(key string) map[string]string { return s.c.SubMap(key) }
+
+// StringMap get a section data map by name
+func (s *Snapshot) StringMap(name string) map[string]string { return s.c.StringMap(name) }
+
+// Section get a section data map. is alias of StringMap()
+func (s *Snapshot) Section(name string) Section { return s.c.Section(name) }
+
+// GetString get a string value, returns error on key not exists
+func (s *Snapshot) GetString(key string) (string, error) { return s.c.GetString(key) }
+
+// GetInt get an int value, returns error on key not exists or parse failed
+func (s *Snapshot) GetInt(key string) (int, error) { return s.c.GetInt(key) }
+
+// GetInt64 get an int64 value, returns error on key not exists or parse failed
+func (s *Snapshot) GetInt64(key string) (int64, error) { return s.c.GetInt64(key) }
+
+// GetUint get an uint value, returns error on key not exists or parse failed
+func (s *Snapshot) GetUint(key string) (uint, error) { return s.c.GetUint(key) }
+
+// GetFloat64 get a float64 value, returns error on key not exists or parse failed
+func (s *Snapshot) GetFloat64(key string) (float64, error) { return s.c.GetFloat64(key) }
+
+// GetBool get a bool value, returns error on key not exists or parse failed
+func (s *Snapshot) GetBool(key string) (bool, error) { return s.c.GetBool(key) }
+
+// GetDuration get a time.Duration value, returns error on key not exists or parse failed
+func (s *Snapshot) GetDuration(key string) (time.Duration, error) { return s.c.GetDuration(key) }
+
+// GetTime get a time.Time value, returns error on key not exists or parse failed
+func (s *Snapshot) GetTime(key string, layout ...string) (time.Time, error) {
+	return s.c.GetTime(key, layout...)
+}
+
+// GetByteSize get a byte size value, returns error on key not exists or parse failed
+func (s *Snapshot) GetByteSize(key string) (uint64, error) { return s.c.GetByteSize(key) }
+
+// MapStruct get config data and binding to the structure. see Ini.MapStruct
+func (s *Snapshot) MapStruct(key string, ptr any) error { return s.c.MapStruct(key, ptr) }
+
+// Decode all data to struct pointer
+func (s *Snapshot) Decode(ptr any) error { return s.c.Decode(ptr) }
+
+// SectionNames get all section names. see Ini.SectionNames
+func (s *Snapshot) SectionNames() []string { return s.c.SectionNames() }
+
+// Keys get all key names of the section. see Ini.Keys
+func (s *Snapshot) Keys(section string) []string { return s.c.Keys(section) }
+
+// KeyPosition get the source position of the key. see Ini.KeyPosition
+func (s *Snapshot) KeyPosition(key string) (parser.Position, bool) { return s.c.KeyPosition(key) }
+
+// IsEmpty snapshot data is empty
+func (s *Snapshot) IsEmpty() bool { return s.c.IsEmpty() }
+
+// Data get all data, returns a copy of the data.
+func (s *Snapshot) Data() map[string]Section { return s.c.Data() }
+
+// Error get the last error of get value or convert value on the snapshot
+func (s *Snapshot) Error() error { return s.c.Error() }
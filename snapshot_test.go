@@ -0,0 +1,170 @@
+package ini_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_Snapshot(t *testing.T) {
+	is := assert.New(t)
+
+	conf := ini.NewWithOptions(ini.ParseVar, ini.KeepOrder)
+	is.NoErr(conf.LoadStrings(`
+name = app
+[db]
+host = localhost
+port = 3306
+url = %(host)s:%(port)s
+tags[] = a
+tags[] = b
+`))
+
+	snap := conf.Snapshot()
+	is.True(snap == conf.Snapshot())
+	is.True(snap.Options().Readonly)
+	is.False(conf.Options().Readonly)
+
+	is.Eq("app", snap.Get("name"))
+	is.Eq(3306, snap.Int("db.port"))
+	is.Eq("localhost:3306", snap.Get("db.url"))
+	is.Eq([]string{"a", "b"}, snap.Slice("db.tags"))
+	is.Eq([]string{"host", "port", "url"}, snap.Keys("db"))
+	is.True(snap.HasSection("db"))
+
+	pos, ok := snap.KeyPosition("db.host")
+	is.True(ok)
+	is.Eq(4, pos.Line)
+
+	// the snapshot is not changed by set, a new snapshot will be built.
+	is.NoErr(conf.Set("port", 3307, "db"))
+	is.True(conf.Delete("name"))
+	is.Eq(3306, snap.Int("db.port"))
+	is.Eq("app", snap.Get("name"))
+
+	snap2 := conf.Snapshot()
+	is.False(snap == snap2)
+	is.Eq(3307, snap2.Int("db.port"))
+	is.False(snap2.HasKey("name"))
+
+	// the returned maps are copies
+	snap2.StringMap("db")["host"] = "changed"
+	snap2.Data()["db"]["host"] = "changed"
+	is.Eq("localhost", snap2.Get("db.host"))
+
+	_, err := snap2.GetInt("db.host")
+	is.ErrSubMsg(err, `cannot convert value "localhost"`)
+
+	// the failed or no-op writes will not publish a new snapshot
+	is.False(conf.Delete("not-exist"))
+	is.False(conf.DelSection("not-exist"))
+	is.Err(conf.Set("", "val"))
+	is.NoErr(conf.LoadStrings("", "; only comments"))
+	is.NoErr(conf.LoadExists("not-exist.ini"))
+	is.True(snap2 == conf.Snapshot())
+}
+
+func TestIni_Snapshot_reload(t *testing.T) {
+	is := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = app\n"), 0644))
+
+	conf := ini.NewWithOptions(ini.ParseResolver)
+	is.NoErr(conf.LoadFiles(file))
+	conf.RegisterResolver("upper", func(arg string) (string, error) {
+		return strings.ToUpper(arg), nil
+	})
+
+	snap := conf.Snapshot()
+	is.Eq("app", snap.Get("name"))
+
+	is.NoErr(os.WriteFile(file, []byte("name = ${upper:new-app}\n"), 0644))
+	is.NoErr(conf.Reload())
+
+	// swapped on reload
+	is.Eq("app", snap.Get("name"))
+	is.Eq("NEW-APP", conf.Snapshot().Get("name"))
+}
+
+func BenchmarkIni_GetValue(b *testing.B) {
+	conf := ini.New()
+	_ = conf.LoadStrings("[db]\nhost = localhost")
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conf.GetValue("db.host")
+		}
+	})
+}
+
+func BenchmarkSnapshot_GetValue(b *testing.B) {
+	conf := ini.New()
+	_ = conf.LoadStrings("[db]\nhost = localhost")
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conf.Snapshot().GetValue("db.host")
+		}
+	})
+}
+
+// the readers of snapshot never contend with the writer, the writer publishes the new snapshot.
+func BenchmarkSnapshot_GetValue_withSet(b *testing.B) {
+	conf := ini.New()
+	_ = conf.LoadStrings("[db]\nhost = localhost")
+
+	conf.Snapshot()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = conf.Set("port", 3306, "db")
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conf.Snapshot().GetValue("db.host")
+		}
+	})
+}
+
+func BenchmarkIni_GetValue_withSet(b *testing.B) {
+	conf := ini.New()
+	_ = conf.LoadStrings("[db]\nhost = localhost")
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = conf.Set("port", 3306, "db")
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			conf.GetValue("db.host")
+		}
+	})
+}
@@ -0,0 +1,356 @@
+package ini
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeDiff the changed keys after reload.
+//
+// Key format is "section.key", the keys of the default section has no section prefix.
+type ChangeDiff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// IsEmpty check has no changes
+func (d ChangeDiff) IsEmpty() bool {
+	return len(d.Added)+len(d.Changed)+len(d.Removed) == 0
+}
+
+// ChangeFunc on config data changed by reload
+type ChangeFunc func(diff ChangeDiff)
+
+// source file info of loaded
+type srcFile struct {
+	path string
+	// is loaded by LoadExists
+	exist bool
+	// is loaded by include directive
+	included bool
+}
+
+// file stat for detect change
+type fileStat struct {
+	size    int64
+	exists  bool
+	modTime time.Time
+}
+
+// polling file watcher
+type fileWatcher struct {
+	stop  chan struct{}
+	done  chan struct{}
+	stats map[string]fileStat
+}
+
+// record loaded source file, use for reload.
+func (c *Ini) addSrcFile(file string, loadExist, included bool) {
+	for _, f := range c.files {
+		if f.path == file {
+			return
+		}
+	}
+	c.files = append(c.files, srcFile{path: file, exist: loadExist, included: included})
+	c.filesGen++
+}
+
+// copy the source files and the generation
+func (c *Ini) srcFiles() ([]srcFile, uint64) {
+	c.rLock()
+	defer c.rUnlock()
+
+	files := make([]srcFile, len(c.files))
+	copy(files, c.files)
+	return files, c.filesGen
+}
+
+// OnChange register change callback for the default instance
+func OnChange(fn ChangeFunc) { dc.OnChange(fn) }
+
+// OnChange register callback, will call it on data changed by reload.
+func (c *Ini) OnChange(fn ChangeFunc) {
+	c.lock.Lock()
+	c.onChange = append(c.onChange, fn)
+	c.lock.Unlock()
+}
+
+// Reload files for the default instance
+func Reload() error { return dc.Reload() }
+
+// Reload re-parse all files loaded by LoadFiles/LoadExists into a fresh data set,
+// then swap it atomically and call the OnChange callbacks.
+//
+// The Reload calls are serialized, the files loaded by LoadFiles on reloading will be parsed again.
+//
+// The OnChange callbacks are called after the reload finished, so they can call Reload() again.
+//
+// NOTICE: the data loaded by LoadStrings, LoadData or Set will be dropped.
+// On Options.Layered=true, only the file layers will be replaced, the other layers will be kept.
+func (c *Ini) Reload() error {
+	diff, fns, err := c.reload()
+	if err != nil {
+		return err
+	}
+
+	if !diff.IsEmpty() {
+		for _, fn := range fns {
+			fn(diff)
+		}
+	}
+	return nil
+}
+
+// reload the files, returns the changes and the callbacks.
+func (c *Ini) reload() (ChangeDiff, []ChangeFunc, error) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	for {
+		files, gen := c.srcFiles()
+		if len(files) == 0 {
+			return ChangeDiff{}, nil, errNoFiles
+		}
+
+		fresh, err := c.parseFiles(files)
+		if err != nil {
+			return ChangeDiff{}, nil, err
+		}
+
+		// the files are changed on parsing. eg: LoadFiles() is called, parse again.
+		if diff, fns, ok := c.swapReloaded(fresh, gen); ok {
+			return diff, fns, nil
+		}
+	}
+}
+
+// parse the source files to a fresh instance
+func (c *Ini) parseFiles(files []srcFile) (*Ini, error) {
+	fresh := &Ini{opts: c.opts, rawBak: make(map[string]string, 6)}
+	fresh.ensureInit()
+	for _, f := range files {
+		// the included files will be loaded by include directive
+		if f.included {
+			continue
+		}
+		if err := fresh.loadSource(f.path, f.exist); err != nil {
+			return nil, err
+		}
+	}
+	return fresh, nil
+}
+
+// swap the reloaded data, returns the changes and callbacks. ok is false on the files generation has been changed.
+func (c *Ini) swapReloaded(fresh *Ini, gen uint64) (diff ChangeDiff, fns []ChangeFunc, ok bool) {
+	// build the new snapshot before swap, if the snapshot is enabled.
+	var snap *Snapshot
+	if c.snap.Load() != nil && !c.opts.Layered {
+		snap = fresh.newSnapshot()
+	}
+
+	c.lock.Lock()
+	if c.filesGen != gen {
+		c.lock.Unlock()
+		return
+	}
+
+	old := flattenData(c.data, c.arrays, c.opts)
+	if c.opts.Layered {
+		// keep the layers not loaded from files
+		c.replaceFileLayers(fresh.layers)
+	} else {
+		c.data = fresh.data
+		c.rawBak = fresh.rawBak
+		c.comments = fresh.comments
+		c.positions = fresh.positions
+		c.order = fresh.order
+		c.arrays = fresh.arrays
+		c.parents = fresh.parents
+		c.doc = fresh.doc
+		if snap != nil {
+			snap.c.resolvers = copyResolvers(c.resolvers)
+		}
+	}
+
+	c.files = fresh.files
+	c.filesGen++
+	if snap != nil {
+		c.snap.Store(snap)
+	} else {
+		c.publishSnapshot()
+	}
+	diff = diffData(old, flattenData(c.data, c.arrays, c.opts))
+	fns = c.onChange
+	c.lock.Unlock()
+	return diff, fns, true
+}
+
+// Watch the loaded files for the default instance
+func Watch(interval time.Duration) error { return dc.Watch(interval) }
+
+// Watch the files loaded by LoadFiles/LoadExists(contains the include files), reload data on the files changed.
+//
+// It is polling-based, check the files modify time and size by interval.
+// On reload failed, the old data will be kept and the error can be got by Ini.Error().
+func (c *Ini) Watch(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("ini: watch interval must be greater than 0")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.files) == 0 {
+		return errNoFiles
+	}
+	if c.watcher != nil {
+		return errors.New("ini: the files are already being watched")
+	}
+
+	w := &fileWatcher{
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		stats: make(map[string]fileStat, len(c.files)),
+	}
+	w.syncFiles(c.files)
+
+	c.watcher = w
+	go c.watching(w, interval)
+	return nil
+}
+
+// StopWatch for the default instance
+func StopWatch() { dc.StopWatch() }
+
+// StopWatch stop watch the files, will wait the watching goroutine exit.
+//
+// NOTICE: the OnChange callbacks of the watcher are called in the watching goroutine,
+// don't call StopWatch in the callbacks, it will be deadlock. please use `go c.StopWatch()` instead.
+func (c *Ini) StopWatch() {
+	c.lock.Lock()
+	w := c.watcher
+	c.watcher = nil
+	c.lock.Unlock()
+
+	if w != nil {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+func (c *Ini) watching(w *fileWatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer func() {
+		ticker.Stop()
+		close(w.done)
+	}()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			// the files may be loaded after Watch(). eg: LoadFiles()
+			files, _ := c.srcFiles()
+			w.syncFiles(files)
+			if !w.detectChanged() {
+				continue
+			}
+
+			if err := c.Reload(); err != nil {
+				c.setErr(err)
+			}
+
+			// the new files may be found by reload. eg: new matches of the include glob
+			files, _ = c.srcFiles()
+			w.syncFiles(files)
+		}
+	}
+}
+
+// sync the watched files by the loaded files, the new files will use the current stat.
+func (w *fileWatcher) syncFiles(files []srcFile) {
+	paths := make(map[string]bool, len(files))
+	for _, f := range files {
+		paths[f.path] = true
+		if _, ok := w.stats[f.path]; !ok {
+			w.stats[f.path] = statFile(f.path)
+		}
+	}
+
+	for path := range w.stats {
+		if !paths[path] {
+			delete(w.stats, path)
+		}
+	}
+}
+
+// detectChanged check and update file stats
+func (w *fileWatcher) detectChanged() (changed bool) {
+	for path, old := range w.stats {
+		st := statFile(path)
+		if st != old {
+			w.stats[path] = st
+			changed = true
+		}
+	}
+	return
+}
+
+func statFile(path string) fileStat {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+}
+
+// flatten the data and array values to a map, key is key path. use for diff.
+func flattenData(data map[string]Section, arrays map[string]map[string][]string, opts *Options) map[string]string {
+	keyPath := func(sec, key string) string {
+		if sec == opts.DefSection {
+			return key
+		}
+		return sec + opts.SectionSep + key
+	}
+
+	mp := make(map[string]string, len(data))
+	for sec, secMp := range data {
+		for key, val := range secMp {
+			mp[keyPath(sec, key)] = val
+		}
+	}
+
+	// the array value is joined by "\x00", keep it different with the string value.
+	for sec, arrMp := range arrays {
+		for key, vals := range arrMp {
+			mp[keyPath(sec, key)] = "\x00" + strings.Join(vals, "\x00")
+		}
+	}
+	return mp
+}
+
+// diff old and new flattened data, returns the changed keys.
+func diffData(old, new map[string]string) (diff ChangeDiff) {
+	for key, val := range new {
+		if oldVal, ok := old[key]; !ok {
+			diff.Added = append(diff.Added, key)
+		} else if oldVal != val {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return
+}
@@ -0,0 +1,167 @@
+package ini_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gookit/goutil/testutil/assert"
+	"github.com/gookit/ini/v2"
+)
+
+func TestIni_Reload(t *testing.T) {
+	is := assert.New(t)
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\nage = 28\n[sec]\nkey = val\n"), 0664))
+
+	conf := ini.New()
+	is.ErrMsg(conf.Reload(), "ini: no files loaded by LoadFiles or LoadExists")
+	is.NoErr(conf.LoadExists(file, "not-exist.ini"))
+	is.NoErr(conf.LoadStrings("other = val"))
+	is.Eq("val", conf.Get("other"))
+
+	var diff ini.ChangeDiff
+	conf.OnChange(func(d ini.ChangeDiff) {
+		diff = d
+	})
+
+	// no change
+	is.NoErr(conf.Reload())
+	is.Eq([]string{"other"}, diff.Removed)
+
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\nage = 30\n[sec]\nkey1 = val\n"), 0664))
+	is.NoErr(conf.Reload())
+	is.Eq([]string{"sec.key1"}, diff.Added)
+	is.Eq([]string{"age"}, diff.Changed)
+	is.Eq([]string{"sec.key"}, diff.Removed)
+	is.Eq(30, conf.Int("age"))
+	is.False(conf.HasKey("sec.key"))
+
+	// array values
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\nage = 30\n[sec]\nkey1 = val\ntags[] = a\n"), 0664))
+	is.NoErr(conf.Reload())
+	is.Eq([]string{"sec.tags"}, diff.Added)
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\nage = 30\n[sec]\nkey1 = val\ntags[] = a\ntags[] = b\n"), 0664))
+	is.NoErr(conf.Reload())
+	is.Eq([]string{"sec.tags"}, diff.Changed)
+	is.Empty(diff.Added)
+	is.Eq([]string{"a", "b"}, conf.Slice("sec.tags"))
+
+	// reload error, keep old data
+	is.NoErr(os.WriteFile(file, []byte("invalid"), 0664))
+	is.Err(conf.Reload())
+	is.Eq(30, conf.Int("age"))
+}
+
+func TestIni_Watch(t *testing.T) {
+	is := assert.New(t)
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\n"), 0664))
+
+	conf := ini.New()
+	is.Err(conf.Watch(time.Millisecond))
+	is.NoErr(conf.LoadFiles(file))
+	is.Err(conf.Watch(0))
+
+	ch := make(chan ini.ChangeDiff, 1)
+	conf.OnChange(func(d ini.ChangeDiff) {
+		ch <- d
+	})
+
+	is.NoErr(conf.Watch(5 * time.Millisecond))
+	is.Err(conf.Watch(5 * time.Millisecond))
+	defer conf.StopWatch()
+
+	is.NoErr(os.WriteFile(file, []byte("name = new name\n"), 0664))
+	select {
+	case diff := <-ch:
+		is.Eq([]string{"name"}, diff.Changed)
+		is.Eq("new name", conf.Get("name"))
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait reload timeout")
+	}
+
+	// the file loaded after Watch() will be watched
+	file2 := filepath.Join(filepath.Dir(file), "db.ini")
+	is.NoErr(os.WriteFile(file2, []byte("[db]\nhost = localhost\n"), 0664))
+	is.NoErr(conf.LoadFiles(file2))
+	time.Sleep(30 * time.Millisecond)
+
+	// change the size, don't depend on the mtime granularity
+	is.NoErr(os.WriteFile(file2, []byte("[db]\nhost = 127.0.0.10\n"), 0664))
+	select {
+	case diff := <-ch:
+		is.Eq([]string{"db.host"}, diff.Changed)
+		is.Eq("127.0.0.10", conf.Get("db.host"))
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait reload timeout")
+	}
+
+	conf.StopWatch()
+	conf.StopWatch()
+}
+
+func TestIni_Reload_concurrent(t *testing.T) {
+	is := assert.New(t)
+	dir := t.TempDir()
+
+	conf := ini.New()
+	file := filepath.Join(dir, "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\n"), 0664))
+	is.NoErr(conf.LoadFiles(file))
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		name := "key" + strconv.Itoa(i)
+		file := filepath.Join(dir, name+".ini")
+		is.NoErr(os.WriteFile(file, []byte(name+" = val\n"), 0664))
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errCh <- conf.Reload()
+		}()
+		go func() {
+			defer wg.Done()
+			errCh <- conf.LoadFiles(file)
+		}()
+	}
+	wg.Wait()
+
+	close(errCh)
+	for err := range errCh {
+		is.NoErr(err)
+	}
+
+	// the files loaded on reloading are not lost
+	is.NoErr(conf.Reload())
+	for i := 0; i < 10; i++ {
+		is.Eq("val", conf.Get("key"+strconv.Itoa(i)))
+	}
+	is.Eq("inhere", conf.Get("name"))
+}
+
+func TestIni_Reload_inCallback(t *testing.T) {
+	is := assert.New(t)
+	file := filepath.Join(t.TempDir(), "app.ini")
+	is.NoErr(os.WriteFile(file, []byte("name = inhere\n"), 0664))
+
+	conf := ini.New()
+	is.NoErr(conf.LoadFiles(file))
+
+	var count int
+	conf.OnChange(func(d ini.ChangeDiff) {
+		count++
+		// reload again in the callback will not be deadlock
+		is.NoErr(conf.Reload())
+	})
+
+	is.NoErr(os.WriteFile(file, []byte("name = new name\n"), 0664))
+	is.NoErr(conf.Reload())
+	is.Eq(1, count)
+	is.Eq("new name", conf.Get("name"))
+}